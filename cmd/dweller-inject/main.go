@@ -0,0 +1,125 @@
+// dweller-inject runs as an init container of pods with vault secret claim
+// data injected. It fetches the data from dweller and writes it to the
+// in-memory volume shared with other containers of the pod.
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultTokenFile is the token of the pod service account, dweller
+// authenticates the pod with it. The injected container is given the token
+// bound to the pod instead.
+const defaultTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "dweller-inject: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	url := os.Getenv("DWELLER_INJECT_URL")
+	path := os.Getenv("DWELLER_INJECT_PATH")
+	if url == "" || path == "" {
+		return fmt.Errorf("DWELLER_INJECT_URL and DWELLER_INJECT_PATH must be set")
+	}
+
+	tokenFile := os.Getenv("DWELLER_TOKEN_FILE")
+	if tokenFile == "" {
+		tokenFile = defaultTokenFile
+	}
+
+	data, err := fetch(url, tokenFile, os.Getenv("DWELLER_CA_BUNDLE"))
+	if err != nil {
+		return err
+	}
+
+	return write(path, data)
+}
+
+// fetch fetches data of the claim from dweller.
+func fetch(url, tokenFile, caBundle string) (map[string]string, error) {
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("read service account token: %v", err)
+	}
+
+	tlsConfig := &tls.Config{}
+	if caBundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+			return nil, fmt.Errorf("no certificates in CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch data: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read data: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch data: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data map[string]string
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decode data: %v", err)
+	}
+	return data, nil
+}
+
+// write writes every value to a file named after its key and all of them to
+// the .env file, which can be sourced by a shell to set environment
+// variables.
+func write(dir string, data map[string]string) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var env bytes.Buffer
+	for _, key := range keys {
+		if strings.ContainsRune(key, filepath.Separator) || key == "." || key == ".." {
+			return fmt.Errorf("invalid key %q", key)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(data[key]), 0444); err != nil {
+			return fmt.Errorf("write %q: %v", key, err)
+		}
+		fmt.Fprintf(&env, "%s='%s'\n", key, strings.Replace(data[key], "'", `'\''`, -1))
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, ".env"), []byte(env.String()), 0444); err != nil {
+		return fmt.Errorf("write .env: %v", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -18,8 +19,118 @@ type Specification struct {
 	// ValutToken defines the Vault token that dweller is authenticating with.
 	ValutToken string `envconfig:"VAULT_TOKEN" required:"true"`
 
+	// VaultHealthInterval defines how often Vault health is checked. Syncing is
+	// paused while Vault is not healthy.
+	VaultHealthInterval time.Duration `envconfig:"VAULT_HEALTH_INTERVAL" default:"5s"`
+
 	// LogLevel defines log level for the logger. By default level is "info".
 	LogLevel string `envconfig:"LOG_LEVEL" default:"info"`
+
+	// WatchNamespaces defines a comma separated list of watched namespaces. All
+	// the namespaces are watched if neither it nor NamespaceSelector is set.
+	WatchNamespaces []string `envconfig:"WATCH_NAMESPACES" required:"false"`
+
+	// NamespaceSelector defines a label selector of watched namespaces. It is
+	// mutually exclusive with WatchNamespaces.
+	NamespaceSelector string `envconfig:"NAMESPACE_SELECTOR" required:"false"`
+
+	// ClaimSelector defines a label selector of watched vault secret claims.
+	ClaimSelector string `envconfig:"CLAIM_SELECTOR" required:"false"`
+
+	// ClaimTemplates enables vault secret claim templates, which requires
+	// permissions to list and watch them cluster-wide.
+	ClaimTemplates bool `envconfig:"CLAIM_TEMPLATES" default:"false"`
+
+	// ClusterClaims enables cluster vault secret claims, which requires
+	// permissions to list and watch namespaces.
+	ClusterClaims bool `envconfig:"CLUSTER_CLAIMS" default:"false"`
+
+	// EnforcePathPolicies restricts Vault paths vault secret claims may read
+	// to the ones allowed by vault path policies of their namespace, which
+	// requires permissions to list and watch namespaces and the policies.
+	EnforcePathPolicies bool `envconfig:"ENFORCE_PATH_POLICIES" default:"false"`
+
+	// Workers defines the number of vault secret claims synced concurrently.
+	Workers int `envconfig:"WORKERS" default:"1"`
+
+	// LeaderElect enables leader election, so that only one of several
+	// replicas syncs vault secret claims at a time.
+	LeaderElect bool `envconfig:"LEADER_ELECT" default:"false"`
+
+	// LeaderElectionNamespace defines the namespace of the leader election
+	// lock.
+	LeaderElectionNamespace string `envconfig:"LEADER_ELECTION_NAMESPACE" default:"default"`
+
+	// LeaderElectionName defines the name of the leader election lock.
+	LeaderElectionName string `envconfig:"LEADER_ELECTION_NAME" default:"dweller"`
+
+	// LeaseDuration defines how long standby replicas wait before taking over
+	// the leadership that is not renewed.
+	LeaseDuration time.Duration `envconfig:"LEADER_ELECTION_LEASE_DURATION" default:"15s"`
+
+	// RenewDeadline defines how long the leader tries to renew the leadership
+	// before giving it up.
+	RenewDeadline time.Duration `envconfig:"LEADER_ELECTION_RENEW_DEADLINE" default:"10s"`
+
+	// RetryPeriod defines how often replicas try to acquire or renew the
+	// leadership.
+	RetryPeriod time.Duration `envconfig:"LEADER_ELECTION_RETRY_PERIOD" default:"2s"`
+
+	// RetryBaseDelay defines the delay of the first retry of failed vault
+	// secret claim, it doubles with every retry.
+	RetryBaseDelay time.Duration `envconfig:"RETRY_BASE_DELAY" default:"1s"`
+
+	// RetryMaxDelay defines the ceiling of the retry delay.
+	RetryMaxDelay time.Duration `envconfig:"RETRY_MAX_DELAY" default:"5m"`
+
+	// MaxRetries defines the number of retries before failed vault secret
+	// claim is parked.
+	MaxRetries int `envconfig:"MAX_RETRIES" default:"8"`
+
+	// ParkedRetryInterval defines the interval of re-attempting parked vault
+	// secret claims.
+	ParkedRetryInterval time.Duration `envconfig:"PARKED_RETRY_INTERVAL" default:"10m"`
+
+	// RolloutQPS defines the number of rollouts of workloads depending on
+	// changed secrets triggered per second.
+	RolloutQPS float32 `envconfig:"ROLLOUT_QPS" default:"0.1"`
+
+	// RolloutBurst defines the number of rollouts triggered at once.
+	RolloutBurst int `envconfig:"ROLLOUT_BURST" default:"5"`
+
+	// WebhookAddr defines the address to serve admission webhooks at. Webhooks
+	// are not served if it is empty.
+	WebhookAddr string `envconfig:"WEBHOOK_ADDR" required:"false"`
+
+	// WebhookCertFile and WebhookKeyFile define the files of the certificate
+	// and the key the webhooks are served with. A self-signed certificate is
+	// generated for WebhookHost if they are empty.
+	WebhookCertFile string `envconfig:"WEBHOOK_CERT_FILE" required:"false"`
+	WebhookKeyFile  string `envconfig:"WEBHOOK_KEY_FILE" required:"false"`
+
+	// WebhookHost defines the host of the self-signed webhook certificate.
+	WebhookHost string `envconfig:"WEBHOOK_HOST" default:"localhost"`
+
+	// WebhookCheckVaultPolicies makes the validating webhook reject vault
+	// secret claims with Vault paths dweller is not allowed to read.
+	WebhookCheckVaultPolicies bool `envconfig:"WEBHOOK_CHECK_VAULT_POLICIES" default:"false"`
+
+	// InjectorImage defines the image of the init container injecting vault
+	// secret claim data into pods.
+	InjectorImage string `envconfig:"INJECTOR_IMAGE" default:"fukt/dweller"`
+
+	// InjectorURL defines the base URL of the webhook server reachable from
+	// pods. Pods are not injected if it is empty.
+	InjectorURL string `envconfig:"INJECTOR_URL" required:"false"`
+
+	// InjectorCAFile defines the file of the CA bundle init containers verify
+	// the webhook server certificate with. The self-signed certificate is its
+	// own bundle.
+	InjectorCAFile string `envconfig:"INJECTOR_CA_FILE" required:"false"`
+
+	// MetricsAddr defines the address to serve metrics at /debug/vars. Metrics
+	// are not served if it is empty.
+	MetricsAddr string `envconfig:"METRICS_ADDR" required:"false"`
 }
 
 // SpecificationFromEnvironment returns specification loaded from environment
@@ -1,17 +1,23 @@
 package main
 
 import (
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/fukt/dweller/pkg/client/clientset/versioned"
 	"github.com/fukt/dweller/pkg/controller"
+	"github.com/fukt/dweller/pkg/pathpolicy"
+	"github.com/fukt/dweller/pkg/secret"
 	"github.com/fukt/dweller/pkg/vault"
 )
 
@@ -29,16 +35,72 @@ func main() {
 	log := logrus.New()
 	log.SetLevel(logLevel)
 
+	// Errors the controller can't return, e.g. of watches, are reported by
+	// client-go through the runtime error handlers. The default handlers are
+	// kept, since one of them backs off hot loops of errors.
+	utilruntime.ErrorHandlers = append(utilruntime.ErrorHandlers, func(err error) {
+		log.Errorf("%v", err)
+	})
+
 	config := mustConfig(s.KubeConfig)
 	kubeClient := mustInitKubernetesClient(config)
 	vaultClient := mustInitVaultClient()
-	asm := vault.NewSecretAssembler(vaultClient)
+	health := vault.NewHealthMonitor(vaultClient, s.VaultHealthInterval, log)
+
+	// The authorizer is kept apart from the interface it's passed as, since
+	// a nil pointer in the interface isn't nil.
+	var (
+		authorizer     *pathpolicy.Authorizer
+		pathAuthorizer secret.PathAuthorizer
+	)
+	if s.EnforcePathPolicies {
+		authorizer = pathpolicy.NewAuthorizer(kubeClient, mustInitClientset(config))
+		pathAuthorizer = authorizer
+	}
+	asm := secret.NewCircuitBreaker(vault.NewSecretAssembler(vaultClient, pathAuthorizer), health)
+
+	options := []controller.Option{
+		controller.WithLogger(log),
+		controller.WithWorkers(s.Workers),
+		controller.WithScope(mustScope(s)),
+		controller.WithHealth(health),
+		controller.WithRetryPolicy(controller.RetryPolicy{
+			BaseDelay:      s.RetryBaseDelay,
+			MaxDelay:       s.RetryMaxDelay,
+			MaxRetries:     s.MaxRetries,
+			ParkedInterval: s.ParkedRetryInterval,
+		}),
+		controller.WithRolloutPolicy(controller.RolloutPolicy{
+			QPS:   s.RolloutQPS,
+			Burst: s.RolloutBurst,
+		}),
+	}
+	if s.ClaimTemplates {
+		options = append(options, controller.WithClaimTemplates())
+	}
+	if s.ClusterClaims {
+		options = append(options, controller.WithClusterClaims())
+	}
+	if authorizer != nil {
+		options = append(options, controller.WithPathPolicies(authorizer, authorizer.PolicyInformer()))
+	}
 
-	c, err := controller.New(config, kubeClient, asm, controller.WithLogger(log))
+	c, err := controller.New(config, kubeClient, asm, options...)
 	if err != nil {
 		panic(err.Error())
 	}
 
+	webhooks := mustWebhookServer(s, config, kubeClient, vaultClient, asm, log)
+
+	if s.MetricsAddr != "" {
+		go func() {
+			// Metrics are registered at the default mux by expvar.
+			if err := http.ListenAndServe(s.MetricsAddr, nil); err != nil {
+				log.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
 	stopCh := make(chan struct{})
 
 	go func() {
@@ -48,9 +110,53 @@ func main() {
 		close(stopCh)
 	}()
 
+	go health.Run(stopCh)
+
+	// Policies are enforced by all the replicas, since the webhooks read
+	// Vault as well.
+	if authorizer != nil {
+		go authorizer.Run(stopCh)
+	}
+
+	// Webhooks are served by all the replicas regardless of the leadership.
+	if webhooks != nil {
+		go func() {
+			if err := webhooks.Run(stopCh); err != nil {
+				log.Fatalf("Webhook server failed: %v", err)
+			}
+		}()
+	}
+
+	if s.LeaderElect {
+		runWithLeaderElection(s, config, kubeClient, c, log, stopCh)
+		return
+	}
+
 	c.Run(stopCh)
 }
 
+func mustScope(s Specification) controller.Scope {
+	scope := controller.Scope{Namespaces: s.WatchNamespaces}
+
+	if s.NamespaceSelector != "" {
+		selector, err := labels.Parse(s.NamespaceSelector)
+		if err != nil {
+			panic("error parsing namespace selector: " + err.Error())
+		}
+		scope.NamespaceSelector = selector
+	}
+
+	if s.ClaimSelector != "" {
+		selector, err := labels.Parse(s.ClaimSelector)
+		if err != nil {
+			panic("error parsing claim selector: " + err.Error())
+		}
+		scope.ClaimSelector = selector
+	}
+
+	return scope
+}
+
 func waitForSignal() {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
@@ -95,6 +201,14 @@ func mustInitKubernetesClient(config *rest.Config) *kubernetes.Clientset {
 	return kubeClient
 }
 
+func mustInitClientset(config *rest.Config) *versioned.Clientset {
+	clientset, err := versioned.NewForConfig(config)
+	if err != nil {
+		panic("error creating client: " + err.Error())
+	}
+	return clientset
+}
+
 func mustInitVaultClient() *vaultapi.Client {
 	cfg := vaultapi.DefaultConfig()
 	if err := cfg.ReadEnvironment(); err != nil {
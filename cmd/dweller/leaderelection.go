@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/fukt/dweller/pkg/controller"
+)
+
+// runWithLeaderElection runs the controller once the replica becomes a leader
+// and blocks until stopCh is closed and the controller is shut down. The
+// replica exits if it loses the leadership.
+func runWithLeaderElection(s Specification, config *rest.Config, client kubernetes.Interface, c *controller.Controller, log *logrus.Logger, stopCh <-chan struct{}) {
+	// Standby replicas keep informer caches warm to take over in seconds.
+	if err := c.WarmUp(stopCh); err != nil {
+		panic("error warming up controller: " + err.Error())
+	}
+
+	identity, err := os.Hostname()
+	if err != nil {
+		panic("error getting leader election identity: " + err.Error())
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events(s.LeaderElectionNamespace)})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "dweller"})
+
+	lock, err := newLeaseLock(
+		config,
+		s.LeaderElectionNamespace,
+		s.LeaderElectionName,
+		resourcelock.ResourceLockConfig{
+			Identity:      identity,
+			EventRecorder: recorder,
+		},
+	)
+	if err != nil {
+		panic("error creating leader election lock: " + err.Error())
+	}
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+
+	go leaderelection.RunOrDie(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: s.LeaseDuration,
+		RenewDeadline: s.RenewDeadline,
+		RetryPeriod:   s.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(_ <-chan struct{}) {
+				log.Infof("Became the leader as %q", identity)
+				close(started)
+				c.Run(stopCh)
+				close(done)
+			},
+			OnStoppedLeading: func() {
+				log.Fatalf("Lost the leadership as %q", identity)
+			},
+		},
+	})
+
+	log.Infof("Waiting for the leadership as %q", identity)
+	<-stopCh
+
+	select {
+	case <-started:
+		<-done
+	default:
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// The client we use does not ship types of coordination.k8s.io, nor the lock
+// on top of them, so Lease is declared here with the same wire format.
+
+// rfc3339Micro is the format of MicroTime of the API server.
+const rfc3339Micro = "2006-01-02T15:04:05.000000Z07:00"
+
+// microTime is a time marshaled with microsecond precision.
+type microTime struct {
+	time.Time
+}
+
+func (t microTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.UTC().Format(rfc3339Micro))
+}
+
+func (t *microTime) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(rfc3339Micro, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed.Local()
+	return nil
+}
+
+// lease is a Lease of coordination.k8s.io/v1.
+type lease struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec leaseSpec `json:"spec"`
+}
+
+type leaseSpec struct {
+	HolderIdentity       *string    `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32     `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *microTime `json:"acquireTime,omitempty"`
+	RenewTime            *microTime `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32     `json:"leaseTransitions,omitempty"`
+}
+
+var leaseGroupVersion = schema.GroupVersion{Group: "coordination.k8s.io", Version: "v1"}
+
+// leaseLock is the leader election lock kept in a Lease.
+type leaseLock struct {
+	namespace string
+	name      string
+	client    rest.Interface
+	config    resourcelock.ResourceLockConfig
+
+	// lease is the last read or written Lease, it's updated in place.
+	lease *lease
+}
+
+var _ resourcelock.Interface = &leaseLock{}
+
+// newLeaseLock returns the lock kept in the Lease with the given name.
+func newLeaseLock(config *rest.Config, namespace, name string, lockConfig resourcelock.ResourceLockConfig) (*leaseLock, error) {
+	cfg := *config
+	cfg.GroupVersion = &leaseGroupVersion
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	client, err := rest.RESTClientFor(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &leaseLock{namespace: namespace, name: name, client: client, config: lockConfig}, nil
+}
+
+// Get returns the election record of the Lease.
+func (ll *leaseLock) Get() (*resourcelock.LeaderElectionRecord, error) {
+	body, err := ll.client.Get().Namespace(ll.namespace).Resource("leases").Name(ll.name).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var l lease
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, err
+	}
+	ll.lease = &l
+	return leaseSpecToRecord(&l.Spec), nil
+}
+
+// Create creates the Lease with the election record.
+func (ll *leaseLock) Create(ler resourcelock.LeaderElectionRecord) error {
+	l := &lease{
+		TypeMeta:   metav1.TypeMeta{APIVersion: leaseGroupVersion.String(), Kind: "Lease"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: ll.namespace, Name: ll.name},
+		Spec:       recordToLeaseSpec(ler),
+	}
+	return ll.write(ll.client.Post().Namespace(ll.namespace).Resource("leases"), l)
+}
+
+// Update updates the election record of the Lease.
+func (ll *leaseLock) Update(ler resourcelock.LeaderElectionRecord) error {
+	if ll.lease == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+
+	l := *ll.lease
+	l.Spec = recordToLeaseSpec(ler)
+	return ll.write(ll.client.Put().Namespace(ll.namespace).Resource("leases").Name(ll.name), &l)
+}
+
+func (ll *leaseLock) write(req *rest.Request, l *lease) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	body, err := req.Body(data).Do().Raw()
+	if err != nil {
+		return err
+	}
+
+	var written lease
+	if err := json.Unmarshal(body, &written); err != nil {
+		return err
+	}
+	ll.lease = &written
+	return nil
+}
+
+// RecordEvent records the event on the Lease.
+func (ll *leaseLock) RecordEvent(s string) {
+	if ll.config.EventRecorder == nil {
+		return
+	}
+
+	ref := &corev1.ObjectReference{
+		APIVersion: leaseGroupVersion.String(),
+		Kind:       "Lease",
+		Namespace:  ll.namespace,
+		Name:       ll.name,
+	}
+	if ll.lease != nil {
+		ref.UID = ll.lease.UID
+	}
+	ll.config.EventRecorder.Eventf(ref, corev1.EventTypeNormal, "LeaderElection", "%v %v", ll.config.Identity, s)
+}
+
+// Describe returns the namespace and the name of the Lease.
+func (ll *leaseLock) Describe() string {
+	return ll.namespace + "/" + ll.name
+}
+
+// Identity returns the identity of the replica.
+func (ll *leaseLock) Identity() string {
+	return ll.config.Identity
+}
+
+func leaseSpecToRecord(spec *leaseSpec) *resourcelock.LeaderElectionRecord {
+	var r resourcelock.LeaderElectionRecord
+	if spec.HolderIdentity != nil {
+		r.HolderIdentity = *spec.HolderIdentity
+	}
+	if spec.LeaseDurationSeconds != nil {
+		r.LeaseDurationSeconds = int(*spec.LeaseDurationSeconds)
+	}
+	if spec.LeaseTransitions != nil {
+		r.LeaderTransitions = int(*spec.LeaseTransitions)
+	}
+	if spec.AcquireTime != nil {
+		r.AcquireTime = metav1.NewTime(spec.AcquireTime.Time)
+	}
+	if spec.RenewTime != nil {
+		r.RenewTime = metav1.NewTime(spec.RenewTime.Time)
+	}
+	return &r
+}
+
+func recordToLeaseSpec(ler resourcelock.LeaderElectionRecord) leaseSpec {
+	duration := int32(ler.LeaseDurationSeconds)
+	transitions := int32(ler.LeaderTransitions)
+	return leaseSpec{
+		HolderIdentity:       &ler.HolderIdentity,
+		LeaseDurationSeconds: &duration,
+		AcquireTime:          &microTime{ler.AcquireTime.Time},
+		RenewTime:            &microTime{ler.RenewTime.Time},
+		LeaseTransitions:     &transitions,
+	}
+}
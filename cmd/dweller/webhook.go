@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/fukt/dweller/pkg/client/clientset/versioned"
+	"github.com/fukt/dweller/pkg/secret"
+	"github.com/fukt/dweller/pkg/vault"
+	"github.com/fukt/dweller/pkg/webhook"
+)
+
+// mustWebhookServer returns the server of admission webhooks or nil if
+// webhooks are disabled.
+func mustWebhookServer(s Specification, config *rest.Config, client kubernetes.Interface, vaultClient *vaultapi.Client, asm secret.Assembler, log *logrus.Logger) *webhook.Server {
+	if s.WebhookAddr == "" {
+		return nil
+	}
+
+	certPEM, keyPEM, caBundle := mustWebhookCertificate(s, log)
+
+	srv, err := webhook.NewServer(s.WebhookAddr, certPEM, keyPEM, log)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	var paths webhook.PathChecker
+	if s.WebhookCheckVaultPolicies {
+		paths = vault.NewPolicyChecker(vaultClient)
+	}
+	webhook.NewValidator(paths, log).Register(srv)
+	webhook.NewConverter(log).Register(srv)
+
+	if s.InjectorURL != "" {
+		clientset, err := versioned.NewForConfig(config)
+		if err != nil {
+			panic("error creating client: " + err.Error())
+		}
+
+		injector := webhook.NewInjector(client, clientset, asm, webhook.InjectorConfig{
+			Image:    s.InjectorImage,
+			URL:      s.InjectorURL,
+			CABundle: caBundle,
+		}, log)
+		injector.Register(srv)
+	}
+
+	return srv
+}
+
+// mustWebhookCertificate returns the certificate and the key of the webhook
+// server along with the CA bundle to verify it with. The certificate is
+// self-signed for local testing unless the files are set.
+func mustWebhookCertificate(s Specification, log *logrus.Logger) (certPEM, keyPEM, caBundle []byte) {
+	var err error
+	if s.WebhookCertFile == "" {
+		certPEM, keyPEM, err = webhook.SelfSignedCertificate(s.WebhookHost)
+		if err != nil {
+			panic("error generating self-signed webhook certificate: " + err.Error())
+		}
+		log.Warnf("Webhooks are served with a self-signed certificate for %q, use caBundle %s", s.WebhookHost, base64.StdEncoding.EncodeToString(certPEM))
+		return certPEM, keyPEM, certPEM
+	}
+
+	certPEM, err = ioutil.ReadFile(s.WebhookCertFile)
+	if err != nil {
+		panic("error reading webhook certificate: " + err.Error())
+	}
+	keyPEM, err = ioutil.ReadFile(s.WebhookKeyFile)
+	if err != nil {
+		panic("error reading webhook key: " + err.Error())
+	}
+	if s.InjectorCAFile != "" {
+		caBundle, err = ioutil.ReadFile(s.InjectorCAFile)
+		if err != nil {
+			panic("error reading injector CA bundle: " + err.Error())
+		}
+	}
+	return certPEM, keyPEM, caBundle
+}
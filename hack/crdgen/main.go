@@ -0,0 +1,264 @@
+// crdgen generates custom resource definitions of dweller resources from the
+// API types, so that the schema can't drift from the types. The definitions
+// are written to stdout:
+//
+//	go run ./hack/crdgen > deployment/custom-resource-definition.yaml
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/build"
+	"os"
+	"path"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
+)
+
+// storageVersion is the version resources are stored in. Other versions are
+// converted from and to it by the conversion webhook of dweller.
+var storageVersion = v1alpha1.SchemeGroupVersion.Version
+
+// conversionWebhook is where the API server reaches the conversion webhook.
+// The CA bundle is specific to the installation, so it's left out.
+var conversionWebhook = webhookClientConfig{
+	Service: serviceReference{Namespace: "dweller", Name: "dweller", Path: "/convert"},
+}
+
+// resource describes the custom resource to generate the definition for.
+type resource struct {
+	comment    string
+	kind       string
+	plural     string
+	singular   string
+	shortNames []string
+	scope      string
+	// objects are the objects of the resource in every served version.
+	objects []interface{}
+	status  bool
+	columns []printerColumn
+}
+
+var resources = []resource{
+	{
+		comment:    "This CustomResourceDefinition defines vault secret claim.",
+		kind:       "VaultSecretClaim",
+		plural:     "vaultsecretclaims",
+		singular:   "vaultsecretclaim",
+		shortNames: []string{"vsc"},
+		scope:      "Namespaced",
+		objects:    []interface{}{v1alpha1.VaultSecretClaim{}, v1beta1.VaultSecretClaim{}},
+		status:     true,
+		columns: []printerColumn{
+			{Name: "Ready", Type: "string", JSONPath: `.status.conditions[?(@.type=="Ready")].status`},
+			{Name: "Secret", Type: "string", JSONPath: ".status.secretName"},
+			{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			{Name: "Last Sync", Type: "date", JSONPath: ".status.lastSyncTime"},
+		},
+	},
+	{
+		comment:    "This CustomResourceDefinition defines cluster-scoped vault secret claim\ntemplate.",
+		kind:       "VaultSecretClaimTemplate",
+		plural:     "vaultsecretclaimtemplates",
+		singular:   "vaultsecretclaimtemplate",
+		shortNames: []string{"vsct"},
+		scope:      "Cluster",
+		objects:    []interface{}{v1alpha1.VaultSecretClaimTemplate{}, v1beta1.VaultSecretClaimTemplate{}},
+	},
+	{
+		comment:    "This CustomResourceDefinition defines cluster vault secret claim, which\nwrites its secret to every selected namespace.",
+		kind:       "ClusterVaultSecretClaim",
+		plural:     "clustervaultsecretclaims",
+		singular:   "clustervaultsecretclaim",
+		shortNames: []string{"cvsc"},
+		scope:      "Cluster",
+		objects:    []interface{}{v1alpha1.ClusterVaultSecretClaim{}},
+		status:     true,
+		columns: []printerColumn{
+			{Name: "Ready", Type: "string", JSONPath: `.status.conditions[?(@.type=="Ready")].status`},
+			{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+			{Name: "Last Sync", Type: "date", JSONPath: ".status.lastSyncTime"},
+		},
+	},
+	{
+		comment:    "This CustomResourceDefinition defines vault path policy, which allows\nclaims of some namespaces to read some Vault paths.",
+		kind:       "VaultPathPolicy",
+		plural:     "vaultpathpolicies",
+		singular:   "vaultpathpolicy",
+		shortNames: []string{"vpp"},
+		scope:      "Cluster",
+		objects:    []interface{}{v1alpha1.VaultPathPolicy{}},
+	},
+}
+
+func main() {
+	out, err := generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "crdgen: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out)
+}
+
+// generate returns the YAML documents of the custom resource definitions.
+func generate() ([]byte, error) {
+	generators := make(map[string]*generator)
+	for _, pkgPath := range []string{typesPkg(v1alpha1.VaultSecretClaim{}), typesPkg(v1beta1.VaultSecretClaim{})} {
+		pkg, err := build.Import(pkgPath, ".", build.FindOnly)
+		if err != nil {
+			return nil, fmt.Errorf("find API types: %v", err)
+		}
+		docs, err := parseDocs(pkg.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("parse API types of %s: %v", pkgPath, err)
+		}
+		generators[pkgPath] = &generator{pkg: pkgPath, docs: docs}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Code generated by hack/crdgen from the API types. DO NOT EDIT.\n")
+	for i, r := range resources {
+		if i > 0 {
+			buf.WriteString("\n---\n")
+		}
+		data, err := yaml.Marshal(definition(r, generators))
+		if err != nil {
+			return nil, fmt.Errorf("marshal %s definition: %v", r.kind, err)
+		}
+
+		buf.WriteString("\n")
+		for _, line := range bytes.Split([]byte(r.comment), []byte("\n")) {
+			fmt.Fprintf(&buf, "# %s\n", line)
+		}
+		buf.WriteString("\n")
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// definition returns the custom resource definition of the resource.
+func definition(r resource, generators map[string]*generator) *customResourceDefinition {
+	var versions []customResourceVersion
+	for _, obj := range r.objects {
+		t := reflect.TypeOf(obj)
+		name := path.Base(t.PkgPath())
+		version := customResourceVersion{
+			Name:                     name,
+			Served:                   true,
+			Storage:                  name == storageVersion,
+			Schema:                   validation{OpenAPIV3Schema: generators[t.PkgPath()].resourceSchema(t)},
+			AdditionalPrinterColumns: r.columns,
+		}
+		if r.status {
+			version.Subresources = &subresources{Status: &struct{}{}}
+		}
+		versions = append(versions, version)
+	}
+
+	crd := &customResourceDefinition{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Spec: customResourceDefinitionSpec{
+			Group: v1alpha1.SchemeGroupVersion.Group,
+			Scope: r.scope,
+			Names: names{
+				Plural:     r.plural,
+				Singular:   r.singular,
+				Kind:       r.kind,
+				ShortNames: r.shortNames,
+			},
+			Versions: versions,
+		},
+	}
+	// Resources served in a single version need no conversion.
+	if len(versions) > 1 {
+		crd.Spec.Conversion = &conversion{
+			Strategy: "Webhook",
+			Webhook: &webhookConversion{
+				ClientConfig:             conversionWebhook,
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+	}
+	crd.Metadata.Name = r.plural + "." + v1alpha1.SchemeGroupVersion.Group
+	return crd
+}
+
+// The following types are the subset of apiextensions.k8s.io/v1 types used by
+// the generated definitions.
+
+type customResourceDefinition struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec customResourceDefinitionSpec `json:"spec"`
+}
+
+type customResourceDefinitionSpec struct {
+	Group      string                  `json:"group"`
+	Scope      string                  `json:"scope"`
+	Names      names                   `json:"names"`
+	Versions   []customResourceVersion `json:"versions"`
+	Conversion *conversion             `json:"conversion,omitempty"`
+}
+
+type names struct {
+	Plural     string   `json:"plural"`
+	Singular   string   `json:"singular"`
+	Kind       string   `json:"kind"`
+	ShortNames []string `json:"shortNames,omitempty"`
+}
+
+type customResourceVersion struct {
+	Name                     string          `json:"name"`
+	Served                   bool            `json:"served"`
+	Storage                  bool            `json:"storage"`
+	Schema                   validation      `json:"schema"`
+	Subresources             *subresources   `json:"subresources,omitempty"`
+	AdditionalPrinterColumns []printerColumn `json:"additionalPrinterColumns,omitempty"`
+}
+
+type conversion struct {
+	Strategy string             `json:"strategy"`
+	Webhook  *webhookConversion `json:"webhook,omitempty"`
+}
+
+type webhookConversion struct {
+	ClientConfig             webhookClientConfig `json:"clientConfig"`
+	ConversionReviewVersions []string            `json:"conversionReviewVersions"`
+}
+
+type webhookClientConfig struct {
+	Service serviceReference `json:"service"`
+}
+
+type serviceReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+}
+
+type validation struct {
+	OpenAPIV3Schema *schema `json:"openAPIV3Schema"`
+}
+
+type subresources struct {
+	Status *struct{} `json:"status,omitempty"`
+}
+
+type printerColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	JSONPath string `json:"jsonPath"`
+}
+
+// typesPkg returns the package of the API types of the object.
+func typesPkg(obj interface{}) string {
+	return reflect.TypeOf(obj).PkgPath()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestGeneratedDefinitions(t *testing.T) {
+	want, err := ioutil.ReadFile("../../deployment/custom-resource-definition.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("deployment/custom-resource-definition.yaml is out of date, run make crd")
+	}
+}
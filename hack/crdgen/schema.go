@@ -0,0 +1,238 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	typeMetaType   = reflect.TypeOf(metav1.TypeMeta{})
+	objectMetaType = reflect.TypeOf(metav1.ObjectMeta{})
+	timeType       = reflect.TypeOf(metav1.Time{})
+	durationType   = reflect.TypeOf(metav1.Duration{})
+)
+
+// schema is the subset of OpenAPI v3 schema used by the generated
+// definitions.
+type schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// docs holds what can't be learned from the API types by reflection.
+type docs struct {
+	// comments are doc comments of types and fields keyed by "Type" and
+	// "Type.Field" without markers.
+	comments map[string]string
+	// optional are fields marked with "+optional" keyed by "Type.Field".
+	optional map[string]bool
+	// enums are values of string constants keyed by their type.
+	enums map[string][]string
+}
+
+// parseDocs parses the sources of the API types in the directory.
+func parseDocs(dir string) (*docs, error) {
+	fset := token.NewFileSet()
+	notTest := func(fi os.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, dir, notTest, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &docs{
+		comments: make(map[string]string),
+		optional: make(map[string]bool),
+		enums:    make(map[string][]string),
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if gen, ok := decl.(*ast.GenDecl); ok {
+					d.addDecl(gen)
+				}
+			}
+		}
+	}
+	return d, nil
+}
+
+func (d *docs) addDecl(gen *ast.GenDecl) {
+	for _, spec := range gen.Specs {
+		switch spec := spec.(type) {
+		case *ast.TypeSpec:
+			doc := spec.Doc
+			if doc == nil {
+				doc = gen.Doc
+			}
+			d.comments[spec.Name.Name] = commentText(doc)
+
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					key := spec.Name.Name + "." + name.Name
+					d.comments[key] = commentText(field.Doc)
+					d.optional[key] = field.Doc != nil && strings.Contains(field.Doc.Text(), "+optional")
+				}
+			}
+
+		case *ast.ValueSpec:
+			typ, ok := spec.Type.(*ast.Ident)
+			if gen.Tok != token.CONST || !ok {
+				continue
+			}
+			for _, value := range spec.Values {
+				lit, ok := value.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				s, err := strconv.Unquote(lit.Value)
+				if err == nil {
+					d.enums[typ.Name] = append(d.enums[typ.Name], s)
+				}
+			}
+		}
+	}
+}
+
+// commentText returns the comment as a single line without markers.
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(group.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "+") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
+
+// generator generates OpenAPI schemas of the API types of a package.
+type generator struct {
+	pkg  string
+	docs *docs
+}
+
+// resourceSchema returns the schema of the resource type. Object metadata is
+// validated by the API server, so the schema leaves it out.
+func (g *generator) resourceSchema(t reflect.Type) *schema {
+	s := g.schemaOf(t)
+	s.Properties["apiVersion"] = &schema{Type: "string"}
+	s.Properties["kind"] = &schema{Type: "string"}
+	s.Properties["metadata"] = &schema{Type: "object"}
+	return s
+}
+
+func (g *generator) schemaOf(t reflect.Type) *schema {
+	switch t {
+	case timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case durationType:
+		// Duration is marshaled as a string like "1h30m".
+		return &schema{Type: "string"}
+	case objectMetaType:
+		// Only labels and annotations of the secret metadata are used.
+		stringMap := &schema{Type: "object", AdditionalProperties: &schema{Type: "string"}}
+		return &schema{
+			Type: "object",
+			Properties: map[string]*schema{
+				"labels":      stringMap,
+				"annotations": stringMap,
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaOf(t.Elem())
+	case reflect.String:
+		s := &schema{Type: "string"}
+		if t.PkgPath() == g.pkg {
+			s.Enum = g.docs.enums[t.Name()]
+		}
+		return s
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	panic("unsupported type " + t.String())
+}
+
+func (g *generator) structSchema(t reflect.Type) *schema {
+	s := &schema{
+		Type:        "object",
+		Description: g.docs.comments[t.Name()],
+		Properties:  make(map[string]*schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty := parseTag(field.Tag.Get("json"))
+		if name == "-" || field.Anonymous && (field.Type == typeMetaType || field.Type == objectMetaType) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schemaOf(field.Type)
+		key := t.Name() + "." + field.Name
+		if doc := g.docs.comments[key]; doc != "" {
+			prop.Description = doc
+		}
+		s.Properties[name] = prop
+
+		// Fields marshaled as null when empty can't be required.
+		switch field.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+		default:
+			if !omitempty && !g.docs.optional[key] {
+				s.Required = append(s.Required, name)
+			}
+		}
+	}
+	return s
+}
+
+// parseTag returns the name of the json tag and whether it has omitempty
+// option.
+func parseTag(tag string) (name string, omitempty bool) {
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty
+}
@@ -0,0 +1,30 @@
+package v1alpha1
+
+// SecretTemplates returns templates of all the secrets produced by the vault
+// secret claim with names defaulted to the claim name. The main secret is
+// omitted if it has no data while additional secrets are declared.
+func (vsc *VaultSecretClaim) SecretTemplates() []SecretTemplate {
+	var templates []SecretTemplate
+	if len(vsc.Spec.Secret.Data) > 0 || len(vsc.Spec.Secrets) == 0 {
+		templates = append(templates, vsc.Spec.Secret)
+	}
+	templates = append(templates, vsc.Spec.Secrets...)
+
+	for i := range templates {
+		if templates[i].Name == "" {
+			templates[i].Name = vsc.Name
+		}
+	}
+
+	return templates
+}
+
+// DataItems returns data items of all the secret templates of the vault secret
+// claim.
+func (vsc *VaultSecretClaim) DataItems() []DataItem {
+	var items []DataItem
+	for _, tmpl := range vsc.SecretTemplates() {
+		items = append(items, tmpl.Data...)
+	}
+	return items
+}
@@ -47,6 +47,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&VaultSecretClaim{},
 		&VaultSecretClaimList{},
+		&VaultSecretClaimTemplate{},
+		&VaultSecretClaimTemplateList{},
+		&ClusterVaultSecretClaim{},
+		&ClusterVaultSecretClaimList{},
+		&VaultPathPolicy{},
+		&VaultPathPolicyList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -3,26 +3,115 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultSecretClaim claims data items from Vault to be written to kubernetes
+// secrets.
 type VaultSecretClaim struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec VaultSecretClaimSpec `json:"spec"`
+	Spec   VaultSecretClaimSpec   `json:"spec"`
+	Status VaultSecretClaimStatus `json:"status,omitempty"`
 }
 
 // VaultSecretClaimSpec is a specification for vault secret claim.
 type VaultSecretClaimSpec struct {
-	Secret SecretTemplate `json:"secret"`
+	Secret SecretTemplate `json:"secret,omitempty"`
+
+	// Secrets are templates of additional secrets produced by the claim from
+	// different subsets of data items.
+	// +optional
+	Secrets []SecretTemplate `json:"secrets,omitempty"`
+
+	// TemplateRef references a cluster-scoped template the secret is rendered
+	// from. If set, Secret is ignored.
+	// +optional
+	TemplateRef *TemplateReference `json:"templateRef,omitempty"`
+
+	// FailurePolicy defines what to do when some of the data items can not be
+	// found in Vault. Defaults to FailAll.
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+
+	// Immutable makes claim produce a new immutable secret named
+	// "<claim>-<hash>" for every distinct content instead of updating a single
+	// secret in place.
+	// +optional
+	Immutable bool `json:"immutable,omitempty"`
+
+	// RevisionHistoryLimit is the number of old immutable secrets to retain.
+	// Defaults to 2.
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// AdoptionPolicy defines whether the claim takes ownership of existing
+	// secrets that are not controlled by anyone. Defaults to Never.
+	// +optional
+	AdoptionPolicy AdoptionPolicy `json:"adoptionPolicy,omitempty"`
+
+	// BackupOnAdoption makes the claim copy the content of a secret to a new
+	// secret before adopting it.
+	// +optional
+	BackupOnAdoption bool `json:"backupOnAdoption,omitempty"`
+
+	// RefreshInterval is the interval of refreshing the claim from Vault. If
+	// set, the claim is not refreshed on the controller resync.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
 }
 
+// AdoptionPolicy defines whether vault secret claim adopts existing secrets.
+type AdoptionPolicy string
+
+const (
+	// AdoptNever never adopts existing secrets, they are reported as
+	// conflicts.
+	AdoptNever AdoptionPolicy = "Never"
+
+	// AdoptIfLabelled adopts existing secrets labelled with
+	// "dweller.io/adopt: <claim name>".
+	AdoptIfLabelled AdoptionPolicy = "IfLabelled"
+
+	// AdoptAlways adopts any existing secret.
+	AdoptAlways AdoptionPolicy = "Always"
+)
+
+// FailurePolicy defines how vault secret claim handles data items missing in
+// Vault.
+type FailurePolicy string
+
+const (
+	// FailAll fails the whole claim if any required data item is missing, no
+	// secret is written at all.
+	FailAll FailurePolicy = "FailAll"
+
+	// SkipMissing writes the secret without the missing data items.
+	SkipMissing FailurePolicy = "SkipMissing"
+
+	// KeepLastKnown writes the secret keeping the last known values of the
+	// missing data items, if there are any.
+	KeepLastKnown FailurePolicy = "KeepLastKnown"
+)
+
 // SecretTemplate is a template for kubernetes secret created by vault secret
 // claim.
 type SecretTemplate struct {
+	// Name is the name of the secret. Defaults to the claim name.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Shared makes the secret shared with other claims. Each claim owns its
+	// own keys of a shared secret, the keys must not collide. Immutable mode
+	// does not apply to shared secrets.
+	// +optional
+	Shared bool `json:"shared,omitempty"`
+
 	Metadata metav1.ObjectMeta `json:"metadata,omitempty"`
 	Data     []DataItem        `json:"data"`
 }
@@ -33,6 +122,166 @@ type DataItem struct {
 	Key        string `json:"key"`
 	VaultPath  string `json:"vaultPath"`
 	VaultField string `json:"vaultField"`
+
+	// Optional marks data item as not required: if it is missing in Vault the
+	// item is skipped regardless of the claim failure policy.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Default is a value used when data item is missing in Vault.
+	// +optional
+	Default *string `json:"default,omitempty"`
+
+	// ConfigMap routes the non-sensitive value to the config map of the claim
+	// instead of the secret.
+	// +optional
+	ConfigMap bool `json:"configMap,omitempty"`
+
+	// Engine is the Vault secret engine the path belongs to. Defaults to kv.
+	// +optional
+	Engine SecretEngine `json:"engine,omitempty"`
+}
+
+// SecretEngine is a Vault secret engine.
+type SecretEngine string
+
+const (
+	// EngineKV is the key-value secret engine of version 1 or any other
+	// engine returning secret fields as the response data.
+	EngineKV SecretEngine = "kv"
+
+	// EngineKVv2 is the versioned key-value secret engine. Vault path must be
+	// the data path, e.g. "secret/data/postgres". The secret is read only if
+	// its version has changed since the last read.
+	EngineKVv2 SecretEngine = "kv-v2"
+)
+
+// TemplateReference references a vault secret claim template.
+type TemplateReference struct {
+	// Name is the name of the template.
+	Name string `json:"name"`
+
+	// Parameters are values of the template parameters.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// VaultSecretClaimStatus is the most recently observed status of vault secret
+// claim.
+type VaultSecretClaimStatus struct {
+	// ObservedGeneration is the most recent generation observed by the
+	// controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest available observations of the claim state.
+	// +optional
+	Conditions []VaultSecretClaimCondition `json:"conditions,omitempty"`
+
+	// LastSyncTime is the time of the last successful sync.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// ItemErrors lists data items that failed during the last sync.
+	// +optional
+	ItemErrors []DataItemError `json:"itemErrors,omitempty"`
+
+	// SkippedItems lists data items that were not fetched from Vault during the
+	// last sync.
+	// +optional
+	SkippedItems []SkippedItem `json:"skippedItems,omitempty"`
+
+	// SecretName is the name of the current secret produced from the first
+	// secret template. It differs from the template name in immutable mode.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// ContentHash is the content hash of the current secret produced from the
+	// first secret template.
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// Secrets lists all the current secrets produced by the claim.
+	// +optional
+	Secrets []SecretStatus `json:"secrets,omitempty"`
+
+	// Adoptions lists existing secrets adopted by the claim.
+	// +optional
+	Adoptions []Adoption `json:"adoptions,omitempty"`
+}
+
+// Adoption describes an existing secret adopted by vault secret claim.
+type Adoption struct {
+	// Secret is the name of the adopted secret.
+	Secret string `json:"secret"`
+
+	// Backup is the name of the secret holding the content the adopted secret
+	// had before the adoption.
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// Time is the time of the adoption.
+	Time metav1.Time `json:"time"`
+}
+
+// SecretStatus describes the current secret produced from a secret template.
+type SecretStatus struct {
+	// Target is the name of the secret template.
+	Target string `json:"target"`
+
+	// Name is the name of the current secret. It differs from the target in
+	// immutable mode.
+	Name string `json:"name"`
+
+	// Hash is the hash of the secret content produced by the claim.
+	Hash string `json:"hash"`
+}
+
+// VaultSecretClaimConditionType is a type of vault secret claim condition.
+type VaultSecretClaimConditionType string
+
+const (
+	// ClaimReady means all the secrets of the claim exist and are owned by
+	// the claim.
+	ClaimReady VaultSecretClaimConditionType = "Ready"
+
+	// ClaimSynced means the last sync of the claim succeeded.
+	ClaimSynced VaultSecretClaimConditionType = "Synced"
+)
+
+// VaultSecretClaimCondition describes the state of vault secret claim at a
+// certain point.
+type VaultSecretClaimCondition struct {
+	// Type of the condition.
+	Type VaultSecretClaimConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition transitioned from one
+	// status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief machine readable reason of the last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message with details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DataItemError describes an error of fetching data item.
+type DataItemError struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// SkippedItem describes data item that was not fetched from Vault and why.
+type SkippedItem struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -47,3 +296,201 @@ type VaultSecretClaimList struct {
 	// Items is the list of Deployments.
 	Items []VaultSecretClaim `json:"items"`
 }
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultSecretClaimTemplate is a cluster-scoped template of the secret that vault
+// secret claims can reference instead of declaring the secret themselves.
+type VaultSecretClaimTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VaultSecretClaimTemplateSpec `json:"spec"`
+}
+
+// VaultSecretClaimTemplateSpec is a specification for vault secret claim
+// template.
+type VaultSecretClaimTemplateSpec struct {
+	// Parameters declares parameters that can be referenced in the secret
+	// template as "$(name)".
+	// +optional
+	Parameters []TemplateParameter `json:"parameters,omitempty"`
+
+	Secret SecretTemplate `json:"secret"`
+}
+
+// TemplateParameter declares a parameter of vault secret claim template.
+type TemplateParameter struct {
+	Name string `json:"name"`
+
+	// Default is a value used when claim does not set the parameter.
+	// Parameters without default value are required.
+	// +optional
+	Default *string `json:"default,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultSecretClaimTemplateList is a list of VaultSecretClaimTemplate's.
+type VaultSecretClaimTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VaultSecretClaimTemplate `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultSecretClaim claims data items from Vault to be written to a
+// kubernetes secret in every namespace matching the namespace selector.
+type ClusterVaultSecretClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterVaultSecretClaimSpec   `json:"spec"`
+	Status ClusterVaultSecretClaimStatus `json:"status,omitempty"`
+}
+
+// ClusterVaultSecretClaimSpec is a specification for cluster vault secret
+// claim.
+type ClusterVaultSecretClaimSpec struct {
+	// NamespaceSelector selects namespaces the secret is written to by their
+	// labels. An empty selector selects all the namespaces.
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// Secret is the template of the secret written to every selected
+	// namespace. Config map items and shared secrets are not supported.
+	Secret SecretTemplate `json:"secret"`
+
+	// Type is the type of the secret, e.g. "kubernetes.io/dockerconfigjson"
+	// for registry credentials. Defaults to Opaque.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// FailurePolicy defines what to do when some of the data items can not be
+	// found in Vault. Defaults to FailAll.
+	// +optional
+	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// ClusterVaultSecretClaimStatus is the most recently observed status of
+// cluster vault secret claim.
+type ClusterVaultSecretClaimStatus struct {
+	// ObservedGeneration is the most recent generation observed by the
+	// controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions are the latest available observations of the claim state.
+	// The claim is ready once the secret is written to all the selected
+	// namespaces.
+	// +optional
+	Conditions []VaultSecretClaimCondition `json:"conditions,omitempty"`
+
+	// LastSyncTime is the time of the last successful sync.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// SkippedItems lists data items that were not fetched from Vault during the
+	// last sync.
+	// +optional
+	SkippedItems []SkippedItem `json:"skippedItems,omitempty"`
+
+	// Namespaces lists the state of the secret in every selected namespace.
+	// +optional
+	Namespaces []NamespaceStatus `json:"namespaces,omitempty"`
+}
+
+// NamespaceStatus describes the secret of cluster vault secret claim in a
+// namespace.
+type NamespaceStatus struct {
+	// Namespace is the name of the namespace.
+	Namespace string `json:"namespace"`
+
+	// Ready is True if the secret exists in the namespace and is owned by the
+	// claim.
+	Ready corev1.ConditionStatus `json:"ready"`
+
+	// Reason is a brief machine readable reason the secret is not ready.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message with details about the reason.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Hash is the hash of the secret content produced by the claim.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterVaultSecretClaimList is a list of ClusterVaultSecretClaim's.
+type ClusterVaultSecretClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ClusterVaultSecretClaim `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultPathPolicy allows vault secret claims of some namespaces to read some
+// Vault paths. Once policies are enforced, claims may read only the paths
+// allowed by the policies of their namespace.
+type VaultPathPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VaultPathPolicySpec `json:"spec"`
+}
+
+// VaultPathPolicySpec is a specification for vault path policy.
+type VaultPathPolicySpec struct {
+	// Namespaces lists names of the namespaces the policy applies to.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector selects the namespaces the policy applies to by their
+	// labels, in addition to the listed ones.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Rules list Vault paths the namespaces are allowed to read.
+	Rules []PathRule `json:"rules"`
+}
+
+// PathRule allows reading Vault paths matching any of the globs.
+type PathRule struct {
+	// Paths are globs of Vault paths, e.g. "secret/data/team-a/*". A star
+	// matches any sequence of characters except "/".
+	Paths []string `json:"paths"`
+
+	// Engines lists secret engines the paths may be read with. Any engine is
+	// allowed if empty.
+	// +optional
+	Engines []SecretEngine `json:"engines,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultPathPolicyList is a list of VaultPathPolicy's.
+type VaultPathPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VaultPathPolicy `json:"items"`
+}
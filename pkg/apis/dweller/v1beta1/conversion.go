@@ -0,0 +1,216 @@
+package v1beta1
+
+import (
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// v1alpha1 is the storage version, objects of other versions are converted
+// from and to it. Both versions have the same fields except data items, whose
+// Vault fields are moved to the Vault source, so the conversion is lossless.
+
+// ConvertFrom converts the v1alpha1 vault secret claim to the claim.
+func (vsc *VaultSecretClaim) ConvertFrom(in *v1alpha1.VaultSecretClaim) {
+	vsc.TypeMeta = in.TypeMeta
+	vsc.APIVersion = SchemeGroupVersion.String()
+	vsc.ObjectMeta = *in.ObjectMeta.DeepCopy()
+
+	spec := &vsc.Spec
+	spec.Secret = secretTemplateFrom(&in.Spec.Secret)
+	spec.Secrets = nil
+	for i := range in.Spec.Secrets {
+		spec.Secrets = append(spec.Secrets, secretTemplateFrom(&in.Spec.Secrets[i]))
+	}
+	spec.TemplateRef = nil
+	if ref := in.Spec.TemplateRef; ref != nil {
+		spec.TemplateRef = &TemplateReference{Name: ref.Name, Parameters: copyMap(ref.Parameters)}
+	}
+	spec.FailurePolicy = FailurePolicy(in.Spec.FailurePolicy)
+	spec.Immutable = in.Spec.Immutable
+	spec.RevisionHistoryLimit = copyInt32(in.Spec.RevisionHistoryLimit)
+	spec.AdoptionPolicy = AdoptionPolicy(in.Spec.AdoptionPolicy)
+	spec.BackupOnAdoption = in.Spec.BackupOnAdoption
+	spec.RefreshInterval = in.Spec.RefreshInterval.DeepCopy()
+
+	vsc.Status = VaultSecretClaimStatus{}
+	status := in.Status.DeepCopy()
+	vsc.Status.ObservedGeneration = status.ObservedGeneration
+	for _, c := range status.Conditions {
+		vsc.Status.Conditions = append(vsc.Status.Conditions, VaultSecretClaimCondition{
+			Type:               VaultSecretClaimConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	vsc.Status.LastSyncTime = status.LastSyncTime
+	for _, e := range status.ItemErrors {
+		vsc.Status.ItemErrors = append(vsc.Status.ItemErrors, DataItemError(e))
+	}
+	for _, s := range status.SkippedItems {
+		vsc.Status.SkippedItems = append(vsc.Status.SkippedItems, SkippedItem(s))
+	}
+	vsc.Status.SecretName = status.SecretName
+	vsc.Status.ContentHash = status.ContentHash
+	for _, s := range status.Secrets {
+		vsc.Status.Secrets = append(vsc.Status.Secrets, SecretStatus(s))
+	}
+	for _, a := range status.Adoptions {
+		vsc.Status.Adoptions = append(vsc.Status.Adoptions, Adoption(a))
+	}
+}
+
+// ConvertTo converts the vault secret claim to v1alpha1.
+func (vsc *VaultSecretClaim) ConvertTo(out *v1alpha1.VaultSecretClaim) {
+	out.TypeMeta = vsc.TypeMeta
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	out.ObjectMeta = *vsc.ObjectMeta.DeepCopy()
+
+	spec := &out.Spec
+	spec.Secret = secretTemplateTo(&vsc.Spec.Secret)
+	spec.Secrets = nil
+	for i := range vsc.Spec.Secrets {
+		spec.Secrets = append(spec.Secrets, secretTemplateTo(&vsc.Spec.Secrets[i]))
+	}
+	spec.TemplateRef = nil
+	if ref := vsc.Spec.TemplateRef; ref != nil {
+		spec.TemplateRef = &v1alpha1.TemplateReference{Name: ref.Name, Parameters: copyMap(ref.Parameters)}
+	}
+	spec.FailurePolicy = v1alpha1.FailurePolicy(vsc.Spec.FailurePolicy)
+	spec.Immutable = vsc.Spec.Immutable
+	spec.RevisionHistoryLimit = copyInt32(vsc.Spec.RevisionHistoryLimit)
+	spec.AdoptionPolicy = v1alpha1.AdoptionPolicy(vsc.Spec.AdoptionPolicy)
+	spec.BackupOnAdoption = vsc.Spec.BackupOnAdoption
+	spec.RefreshInterval = vsc.Spec.RefreshInterval.DeepCopy()
+
+	out.Status = v1alpha1.VaultSecretClaimStatus{}
+	status := vsc.Status.DeepCopy()
+	out.Status.ObservedGeneration = status.ObservedGeneration
+	for _, c := range status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, v1alpha1.VaultSecretClaimCondition{
+			Type:               v1alpha1.VaultSecretClaimConditionType(c.Type),
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	out.Status.LastSyncTime = status.LastSyncTime
+	for _, e := range status.ItemErrors {
+		out.Status.ItemErrors = append(out.Status.ItemErrors, v1alpha1.DataItemError(e))
+	}
+	for _, s := range status.SkippedItems {
+		out.Status.SkippedItems = append(out.Status.SkippedItems, v1alpha1.SkippedItem(s))
+	}
+	out.Status.SecretName = status.SecretName
+	out.Status.ContentHash = status.ContentHash
+	for _, s := range status.Secrets {
+		out.Status.Secrets = append(out.Status.Secrets, v1alpha1.SecretStatus(s))
+	}
+	for _, a := range status.Adoptions {
+		out.Status.Adoptions = append(out.Status.Adoptions, v1alpha1.Adoption(a))
+	}
+}
+
+// ConvertFrom converts the v1alpha1 vault secret claim template to the
+// template.
+func (t *VaultSecretClaimTemplate) ConvertFrom(in *v1alpha1.VaultSecretClaimTemplate) {
+	t.TypeMeta = in.TypeMeta
+	t.APIVersion = SchemeGroupVersion.String()
+	t.ObjectMeta = *in.ObjectMeta.DeepCopy()
+
+	t.Spec.Parameters = nil
+	for _, p := range in.Spec.Parameters {
+		t.Spec.Parameters = append(t.Spec.Parameters, TemplateParameter{Name: p.Name, Default: copyString(p.Default)})
+	}
+	t.Spec.Secret = secretTemplateFrom(&in.Spec.Secret)
+}
+
+// ConvertTo converts the vault secret claim template to v1alpha1.
+func (t *VaultSecretClaimTemplate) ConvertTo(out *v1alpha1.VaultSecretClaimTemplate) {
+	out.TypeMeta = t.TypeMeta
+	out.APIVersion = v1alpha1.SchemeGroupVersion.String()
+	out.ObjectMeta = *t.ObjectMeta.DeepCopy()
+
+	out.Spec.Parameters = nil
+	for _, p := range t.Spec.Parameters {
+		out.Spec.Parameters = append(out.Spec.Parameters, v1alpha1.TemplateParameter{Name: p.Name, Default: copyString(p.Default)})
+	}
+	out.Spec.Secret = secretTemplateTo(&t.Spec.Secret)
+}
+
+func secretTemplateFrom(in *v1alpha1.SecretTemplate) SecretTemplate {
+	tmpl := SecretTemplate{
+		Name:     in.Name,
+		Shared:   in.Shared,
+		Metadata: *in.Metadata.DeepCopy(),
+	}
+	for _, item := range in.Data {
+		out := DataItem{
+			Key:       item.Key,
+			Optional:  item.Optional,
+			Default:   copyString(item.Default),
+			ConfigMap: item.ConfigMap,
+		}
+		// An item without any Vault field is an item without source.
+		if item.VaultPath != "" || item.VaultField != "" || item.Engine != "" {
+			out.ValueFrom.Vault = &VaultSource{
+				Path:   item.VaultPath,
+				Field:  item.VaultField,
+				Engine: SecretEngine(item.Engine),
+			}
+		}
+		tmpl.Data = append(tmpl.Data, out)
+	}
+	return tmpl
+}
+
+func secretTemplateTo(in *SecretTemplate) v1alpha1.SecretTemplate {
+	tmpl := v1alpha1.SecretTemplate{
+		Name:     in.Name,
+		Shared:   in.Shared,
+		Metadata: *in.Metadata.DeepCopy(),
+	}
+	for _, item := range in.Data {
+		out := v1alpha1.DataItem{
+			Key:       item.Key,
+			Optional:  item.Optional,
+			Default:   copyString(item.Default),
+			ConfigMap: item.ConfigMap,
+		}
+		if src := item.ValueFrom.Vault; src != nil {
+			out.VaultPath = src.Path
+			out.VaultField = src.Field
+			out.Engine = v1alpha1.SecretEngine(src.Engine)
+		}
+		tmpl.Data = append(tmpl.Data, out)
+	}
+	return tmpl
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+func copyInt32(i *int32) *int32 {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	return &c
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,256 @@
+// Package validation validates dweller resources.
+package validation
+
+import (
+	"path"
+	"strings"
+
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+var (
+	failurePolicies  = []string{string(v1alpha1.FailAll), string(v1alpha1.SkipMissing), string(v1alpha1.KeepLastKnown)}
+	adoptionPolicies = []string{string(v1alpha1.AdoptNever), string(v1alpha1.AdoptIfLabelled), string(v1alpha1.AdoptAlways)}
+	engines          = []string{string(v1alpha1.EngineKV), string(v1alpha1.EngineKVv2)}
+)
+
+// ValidateVaultSecretClaim validates the spec of vault secret claim. The
+// secret of a claim referencing a template is rendered later, so only the
+// reference is validated then.
+func ValidateVaultSecretClaim(vsc *v1alpha1.VaultSecretClaim) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := vsc.Spec
+	specPath := field.NewPath("spec")
+
+	if spec.FailurePolicy != "" && !contains(failurePolicies, string(spec.FailurePolicy)) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("failurePolicy"), spec.FailurePolicy, failurePolicies))
+	}
+	if spec.AdoptionPolicy != "" && !contains(adoptionPolicies, string(spec.AdoptionPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("adoptionPolicy"), spec.AdoptionPolicy, adoptionPolicies))
+	}
+	if spec.RevisionHistoryLimit != nil && *spec.RevisionHistoryLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("revisionHistoryLimit"), *spec.RevisionHistoryLimit, "must be greater than or equal to 0"))
+	}
+	if spec.RefreshInterval != nil && spec.RefreshInterval.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("refreshInterval"), spec.RefreshInterval.Duration.String(), "must be greater than 0"))
+	}
+
+	// Secrets are named after the claim by default. The name of the secret
+	// rendered from a template is not known until it's rendered.
+	names := make(map[string]bool)
+	if ref := spec.TemplateRef; ref != nil {
+		if ref.Name == "" {
+			allErrs = append(allErrs, field.Required(specPath.Child("templateRef", "name"), ""))
+		}
+	} else if len(spec.Secret.Data) > 0 || len(spec.Secrets) == 0 {
+		allErrs = append(allErrs, ValidateSecretTemplate(&spec.Secret, specPath.Child("secret"))...)
+		names[secretName(&spec.Secret, vsc.Name)] = true
+	}
+
+	for i := range spec.Secrets {
+		tmpl := &spec.Secrets[i]
+		tmplPath := specPath.Child("secrets").Index(i)
+		allErrs = append(allErrs, ValidateSecretTemplate(tmpl, tmplPath)...)
+
+		name := secretName(tmpl, vsc.Name)
+		if names[name] {
+			allErrs = append(allErrs, field.Duplicate(tmplPath.Child("name"), name))
+		}
+		names[name] = true
+	}
+
+	if spec.Immutable {
+		for _, msg := range validation.IsValidLabelValue(vsc.Name) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), vsc.Name, "must be a label value of immutable secrets: "+msg))
+		}
+		if spec.TemplateRef == nil && (len(spec.Secret.Data) > 0 || len(spec.Secrets) == 0) {
+			allErrs = append(allErrs, ValidateImmutableSecretName(&spec.Secret, vsc.Name, specPath.Child("secret"))...)
+		}
+		for i := range spec.Secrets {
+			allErrs = append(allErrs, ValidateImmutableSecretName(&spec.Secrets[i], vsc.Name, specPath.Child("secrets").Index(i))...)
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateImmutableSecretName validates the name of the secret produced from
+// the template in immutable mode. The name is set as a label value of
+// immutable secrets, which also keeps "<name>-<hash>" within the limit of
+// secret names. Immutable mode does not apply to shared secrets.
+func ValidateImmutableSecretName(tmpl *v1alpha1.SecretTemplate, claim string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if tmpl.Shared {
+		return allErrs
+	}
+
+	name := secretName(tmpl, claim)
+	for _, msg := range validation.IsValidLabelValue(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), name, "must be a label value of immutable secrets: "+msg))
+	}
+	return allErrs
+}
+
+// ValidateClusterVaultSecretClaim validates the spec of cluster vault secret
+// claim. The secret is written to many namespaces on its own, so it can't be
+// shared and can't have config map items.
+func ValidateClusterVaultSecretClaim(cvsc *v1alpha1.ClusterVaultSecretClaim) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := cvsc.Spec
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(&spec.NamespaceSelector, specPath.Child("namespaceSelector"))...)
+	if spec.FailurePolicy != "" && !contains(failurePolicies, string(spec.FailurePolicy)) {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("failurePolicy"), spec.FailurePolicy, failurePolicies))
+	}
+
+	secretPath := specPath.Child("secret")
+	allErrs = append(allErrs, ValidateSecretTemplate(&spec.Secret, secretPath)...)
+	if spec.Secret.Shared {
+		allErrs = append(allErrs, field.Forbidden(secretPath.Child("shared"), "shared secrets are not supported by cluster claims"))
+	}
+	for i, item := range spec.Secret.Data {
+		if item.ConfigMap {
+			allErrs = append(allErrs, field.Forbidden(secretPath.Child("data").Index(i).Child("configMap"), "config map items are not supported by cluster claims"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateVaultPathPolicy validates the spec of vault path policy. A policy
+// must apply to some namespaces and allow some paths.
+func ValidateVaultPathPolicy(vpp *v1alpha1.VaultPathPolicy) field.ErrorList {
+	var allErrs field.ErrorList
+	spec := vpp.Spec
+	specPath := field.NewPath("spec")
+
+	for i, name := range spec.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(name) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("namespaces").Index(i), name, msg))
+		}
+	}
+	if spec.NamespaceSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.NamespaceSelector, specPath.Child("namespaceSelector"))...)
+	} else if len(spec.Namespaces) == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("namespaces"), "namespaces or namespace selector must be set"))
+	}
+
+	rulesPath := specPath.Child("rules")
+	if len(spec.Rules) == 0 {
+		allErrs = append(allErrs, field.Required(rulesPath, ""))
+	}
+	for i, rule := range spec.Rules {
+		rulePath := rulesPath.Index(i)
+		if len(rule.Paths) == 0 {
+			allErrs = append(allErrs, field.Required(rulePath.Child("paths"), ""))
+		}
+		for j, glob := range rule.Paths {
+			if _, err := path.Match(glob, ""); err != nil || glob == "" {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("paths").Index(j), glob, "must be a glob of Vault paths"))
+			}
+		}
+		for j, engine := range rule.Engines {
+			if !contains(engines, string(engine)) {
+				allErrs = append(allErrs, field.NotSupported(rulePath.Child("engines").Index(j), engine, engines))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateSecretTemplate validates the secret template of vault secret claim.
+func ValidateSecretTemplate(tmpl *v1alpha1.SecretTemplate, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if tmpl.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(tmpl.Name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), tmpl.Name, msg))
+		}
+	}
+
+	metaPath := fldPath.Child("metadata")
+	allErrs = append(allErrs, metav1validation.ValidateLabels(tmpl.Metadata.Labels, metaPath.Child("labels"))...)
+	allErrs = append(allErrs, apivalidation.ValidateAnnotations(tmpl.Metadata.Annotations, metaPath.Child("annotations"))...)
+
+	dataPath := fldPath.Child("data")
+	keys := make(map[string]bool, len(tmpl.Data))
+	for i := range tmpl.Data {
+		item := &tmpl.Data[i]
+		itemPath := dataPath.Index(i)
+
+		allErrs = append(allErrs, validateDataItem(item, itemPath)...)
+		if item.Key != "" && keys[item.Key] {
+			allErrs = append(allErrs, field.Duplicate(itemPath.Child("key"), item.Key))
+		}
+		keys[item.Key] = true
+	}
+
+	return allErrs
+}
+
+func validateDataItem(item *v1alpha1.DataItem, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if item.Key == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), ""))
+	} else {
+		for _, msg := range validation.IsConfigMapKey(item.Key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), item.Key, msg))
+		}
+	}
+
+	if item.VaultPath == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("vaultPath"), ""))
+	} else {
+		for _, msg := range IsVaultPath(item.VaultPath) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("vaultPath"), item.VaultPath, msg))
+		}
+	}
+	if item.VaultField == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("vaultField"), ""))
+	}
+
+	switch {
+	case item.Engine != "" && !contains(engines, string(item.Engine)):
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("engine"), item.Engine, engines))
+	case item.Engine == v1alpha1.EngineKVv2 && item.VaultPath != "" && !strings.Contains(item.VaultPath, "/data/"):
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("vaultPath"), item.VaultPath, `must be a data path of KV v2 engine, e.g. "secret/data/postgres"`))
+	}
+
+	return allErrs
+}
+
+// IsVaultPath tests that the Vault path has no empty, "." or ".." segments,
+// which would let it escape the paths allowed by vault path policies.
+func IsVaultPath(p string) []string {
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return []string{`must not contain empty, "." or ".." segments`}
+		}
+	}
+	return nil
+}
+
+// secretName returns the name of the secret produced from the template.
+func secretName(tmpl *v1alpha1.SecretTemplate, claim string) string {
+	if tmpl.Name != "" {
+		return tmpl.Name
+	}
+	return claim
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+func TestValidateVaultSecretClaim(t *testing.T) {
+	item := v1alpha1.DataItem{Key: "PASSWORD", VaultPath: "secret/postgres", VaultField: "password"}
+
+	tests := []struct {
+		name   string
+		spec   v1alpha1.VaultSecretClaimSpec
+		fields []string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret:  v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item}},
+				Secrets: []v1alpha1.SecretTemplate{{Name: "replication", Data: []v1alpha1.DataItem{item}}},
+			},
+		},
+		{
+			name: "empty key and missing path",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{{VaultField: "password"}}},
+			},
+			fields: []string{"spec.secret.data[0].key", "spec.secret.data[0].vaultPath"},
+		},
+		{
+			name: "duplicate keys",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item, item}},
+			},
+			fields: []string{"spec.secret.data[1].key"},
+		},
+		{
+			name: "duplicate secret names",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret:  v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item}},
+				Secrets: []v1alpha1.SecretTemplate{{Data: []v1alpha1.DataItem{item}}},
+			},
+			fields: []string{"spec.secrets[0].name"},
+		},
+		{
+			name: "invalid label key",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{
+					Metadata: metav1.ObjectMeta{Labels: map[string]string{"app/name/x": "postgres"}},
+					Data:     []v1alpha1.DataItem{item},
+				},
+			},
+			fields: []string{"spec.secret.metadata.labels"},
+		},
+		{
+			name: "not a KV v2 data path",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{{Key: "PASSWORD", VaultPath: "secret/postgres", VaultField: "password", Engine: v1alpha1.EngineKVv2}}},
+			},
+			fields: []string{"spec.secret.data[0].vaultPath"},
+		},
+		{
+			name: "path escaping its prefix",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{
+					{Key: "PASSWORD", VaultPath: "secret/team-a/../team-b/postgres", VaultField: "password"},
+					{Key: "USER", VaultPath: "secret//postgres/", VaultField: "user"},
+				}},
+			},
+			fields: []string{"spec.secret.data[0].vaultPath", "spec.secret.data[1].vaultPath"},
+		},
+		{
+			name: "immutable secret name too long for a label",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				Immutable: true,
+				Secret:    v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item}},
+				Secrets: []v1alpha1.SecretTemplate{
+					{Name: strings.Repeat("a", 64), Data: []v1alpha1.DataItem{item}},
+					{Name: strings.Repeat("b", 64), Shared: true, Data: []v1alpha1.DataItem{item}},
+				},
+			},
+			fields: []string{"spec.secrets[0].name"},
+		},
+		{
+			name: "template reference",
+			spec: v1alpha1.VaultSecretClaimSpec{
+				TemplateRef: &v1alpha1.TemplateReference{},
+			},
+			fields: []string{"spec.templateRef.name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vsc := &v1alpha1.VaultSecretClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "postgres"},
+				Spec:       tt.spec,
+			}
+
+			errs := ValidateVaultSecretClaim(vsc)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("got errors %v, want errors of %v", errs, tt.fields)
+			}
+			for i, err := range errs {
+				if err.Field != tt.fields[i] {
+					t.Errorf("got error of %q, want error of %q", err.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateClusterVaultSecretClaim(t *testing.T) {
+	item := v1alpha1.DataItem{Key: "ca.crt", VaultPath: "secret/ca", VaultField: "certificate"}
+
+	tests := []struct {
+		name   string
+		spec   v1alpha1.ClusterVaultSecretClaimSpec
+		fields []string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha1.ClusterVaultSecretClaimSpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				Secret:            v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item}},
+			},
+		},
+		{
+			name: "invalid selector",
+			spec: v1alpha1.ClusterVaultSecretClaimSpec{
+				NamespaceSelector: metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpIn},
+				}},
+				Secret: v1alpha1.SecretTemplate{Data: []v1alpha1.DataItem{item}},
+			},
+			fields: []string{"spec.namespaceSelector.matchExpressions[0].values"},
+		},
+		{
+			name: "shared secret with config map item",
+			spec: v1alpha1.ClusterVaultSecretClaimSpec{
+				Secret: v1alpha1.SecretTemplate{
+					Shared: true,
+					Data:   []v1alpha1.DataItem{{Key: "HOST", VaultPath: "secret/postgres", VaultField: "host", ConfigMap: true}},
+				},
+			},
+			fields: []string{"spec.secret.shared", "spec.secret.data[0].configMap"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cvsc := &v1alpha1.ClusterVaultSecretClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+				Spec:       tt.spec,
+			}
+
+			errs := ValidateClusterVaultSecretClaim(cvsc)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("got errors %v, want errors of %v", errs, tt.fields)
+			}
+			for i, err := range errs {
+				if err.Field != tt.fields[i] {
+					t.Errorf("got error of %q, want error of %q", err.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVaultPathPolicy(t *testing.T) {
+	rule := v1alpha1.PathRule{Paths: []string{"secret/data/payments/*"}, Engines: []v1alpha1.SecretEngine{v1alpha1.EngineKVv2}}
+
+	tests := []struct {
+		name   string
+		spec   v1alpha1.VaultPathPolicySpec
+		fields []string
+	}{
+		{
+			name: "valid",
+			spec: v1alpha1.VaultPathPolicySpec{
+				Namespaces: []string{"payments"},
+				Rules:      []v1alpha1.PathRule{rule},
+			},
+		},
+		{
+			name:   "no namespaces and rules",
+			spec:   v1alpha1.VaultPathPolicySpec{},
+			fields: []string{"spec.namespaces", "spec.rules"},
+		},
+		{
+			name: "malformed glob and unsupported engine",
+			spec: v1alpha1.VaultPathPolicySpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				Rules: []v1alpha1.PathRule{
+					{Paths: []string{"secret/[payments"}, Engines: []v1alpha1.SecretEngine{"pki"}},
+				},
+			},
+			fields: []string{"spec.rules[0].paths[0]", "spec.rules[0].engines[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpp := &v1alpha1.VaultPathPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+				Spec:       tt.spec,
+			}
+
+			errs := ValidateVaultPathPolicy(vpp)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("got errors %v, want errors of %v", errs, tt.fields)
+			}
+			for i, err := range errs {
+				if err.Field != tt.fields[i] {
+					t.Errorf("got error of %q, want error of %q", err.Field, tt.fields[i])
+				}
+			}
+		})
+	}
+}
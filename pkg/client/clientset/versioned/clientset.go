@@ -18,6 +18,7 @@ package versioned
 
 import (
 	dwellerv1alpha1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1alpha1"
+	dwellerv1beta1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1beta1"
 	glog "github.com/golang/glog"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -27,6 +28,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	DwellerV1alpha1() dwellerv1alpha1.DwellerV1alpha1Interface
+	DwellerV1beta1() dwellerv1beta1.DwellerV1beta1Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Dweller() dwellerv1alpha1.DwellerV1alpha1Interface
 }
@@ -36,6 +38,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	dwellerV1alpha1 *dwellerv1alpha1.DwellerV1alpha1Client
+	dwellerV1beta1  *dwellerv1beta1.DwellerV1beta1Client
 }
 
 // DwellerV1alpha1 retrieves the DwellerV1alpha1Client
@@ -43,6 +46,11 @@ func (c *Clientset) DwellerV1alpha1() dwellerv1alpha1.DwellerV1alpha1Interface {
 	return c.dwellerV1alpha1
 }
 
+// DwellerV1beta1 retrieves the DwellerV1beta1Client
+func (c *Clientset) DwellerV1beta1() dwellerv1beta1.DwellerV1beta1Interface {
+	return c.dwellerV1beta1
+}
+
 // Deprecated: Dweller retrieves the default version of DwellerClient.
 // Please explicitly pick a version.
 func (c *Clientset) Dweller() dwellerv1alpha1.DwellerV1alpha1Interface {
@@ -69,6 +77,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.dwellerV1beta1, err = dwellerv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -83,6 +95,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.dwellerV1alpha1 = dwellerv1alpha1.NewForConfigOrDie(c)
+	cs.dwellerV1beta1 = dwellerv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -92,6 +105,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.dwellerV1alpha1 = dwellerv1alpha1.New(c)
+	cs.dwellerV1beta1 = dwellerv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
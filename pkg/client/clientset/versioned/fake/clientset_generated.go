@@ -20,6 +20,8 @@ import (
 	clientset "github.com/fukt/dweller/pkg/client/clientset/versioned"
 	dwellerv1alpha1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1alpha1"
 	fakedwellerv1alpha1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1alpha1/fake"
+	dwellerv1beta1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1beta1"
+	fakedwellerv1beta1 "github.com/fukt/dweller/pkg/client/clientset/versioned/typed/dweller/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -73,6 +75,11 @@ func (c *Clientset) DwellerV1alpha1() dwellerv1alpha1.DwellerV1alpha1Interface {
 	return &fakedwellerv1alpha1.FakeDwellerV1alpha1{Fake: &c.Fake}
 }
 
+// DwellerV1beta1 retrieves the DwellerV1beta1Client
+func (c *Clientset) DwellerV1beta1() dwellerv1beta1.DwellerV1beta1Interface {
+	return &fakedwellerv1beta1.FakeDwellerV1beta1{Fake: &c.Fake}
+}
+
 // Dweller retrieves the DwellerV1alpha1Client
 func (c *Clientset) Dweller() dwellerv1alpha1.DwellerV1alpha1Interface {
 	return &fakedwellerv1alpha1.FakeDwellerV1alpha1{Fake: &c.Fake}
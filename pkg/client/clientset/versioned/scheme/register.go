@@ -18,6 +18,7 @@ package scheme
 
 import (
 	dwellerv1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	dwellerv1beta1 "github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -49,4 +50,5 @@ func init() {
 // correctly.
 func AddToScheme(scheme *runtime.Scheme) {
 	dwellerv1alpha1.AddToScheme(scheme)
+	dwellerv1beta1.AddToScheme(scheme)
 }
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	scheme "github.com/fukt/dweller/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ClusterVaultSecretClaimsGetter has a method to return a ClusterVaultSecretClaimInterface.
+// A group's client should implement this interface.
+type ClusterVaultSecretClaimsGetter interface {
+	ClusterVaultSecretClaims() ClusterVaultSecretClaimInterface
+}
+
+// ClusterVaultSecretClaimInterface has methods to work with ClusterVaultSecretClaim resources.
+type ClusterVaultSecretClaimInterface interface {
+	Create(*v1alpha1.ClusterVaultSecretClaim) (*v1alpha1.ClusterVaultSecretClaim, error)
+	Update(*v1alpha1.ClusterVaultSecretClaim) (*v1alpha1.ClusterVaultSecretClaim, error)
+	UpdateStatus(*v1alpha1.ClusterVaultSecretClaim) (*v1alpha1.ClusterVaultSecretClaim, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.ClusterVaultSecretClaim, error)
+	List(opts v1.ListOptions) (*v1alpha1.ClusterVaultSecretClaimList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultSecretClaim, err error)
+	ClusterVaultSecretClaimExpansion
+}
+
+// clusterVaultSecretClaims implements ClusterVaultSecretClaimInterface
+type clusterVaultSecretClaims struct {
+	client rest.Interface
+}
+
+// newClusterVaultSecretClaims returns a ClusterVaultSecretClaims
+func newClusterVaultSecretClaims(c *DwellerV1alpha1Client) *clusterVaultSecretClaims {
+	return &clusterVaultSecretClaims{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the clusterVaultSecretClaim, and returns the corresponding clusterVaultSecretClaim object, and an error if there is any.
+func (c *clusterVaultSecretClaims) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaim{}
+	err = c.client.Get().
+		Resource("clustervaultsecretclaims").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ClusterVaultSecretClaims that match those selectors.
+func (c *clusterVaultSecretClaims) List(opts v1.ListOptions) (result *v1alpha1.ClusterVaultSecretClaimList, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaimList{}
+	err = c.client.Get().
+		Resource("clustervaultsecretclaims").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested clusterVaultSecretClaims.
+func (c *clusterVaultSecretClaims) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("clustervaultsecretclaims").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a clusterVaultSecretClaim and creates it.  Returns the server's representation of the clusterVaultSecretClaim, and an error, if there is any.
+func (c *clusterVaultSecretClaims) Create(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaim{}
+	err = c.client.Post().
+		Resource("clustervaultsecretclaims").
+		Body(clusterVaultSecretClaim).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a clusterVaultSecretClaim and updates it. Returns the server's representation of the clusterVaultSecretClaim, and an error, if there is any.
+func (c *clusterVaultSecretClaims) Update(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaim{}
+	err = c.client.Put().
+		Resource("clustervaultsecretclaims").
+		Name(clusterVaultSecretClaim.Name).
+		Body(clusterVaultSecretClaim).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *clusterVaultSecretClaims) UpdateStatus(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaim{}
+	err = c.client.Put().
+		Resource("clustervaultsecretclaims").
+		Name(clusterVaultSecretClaim.Name).
+		SubResource("status").
+		Body(clusterVaultSecretClaim).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the clusterVaultSecretClaim and deletes it. Returns an error if one occurs.
+func (c *clusterVaultSecretClaims) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("clustervaultsecretclaims").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *clusterVaultSecretClaims) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("clustervaultsecretclaims").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched clusterVaultSecretClaim.
+func (c *clusterVaultSecretClaims) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	result = &v1alpha1.ClusterVaultSecretClaim{}
+	err = c.client.Patch(pt).
+		Resource("clustervaultsecretclaims").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -25,7 +25,10 @@ import (
 
 type DwellerV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterVaultSecretClaimsGetter
+	VaultPathPoliciesGetter
 	VaultSecretClaimsGetter
+	VaultSecretClaimTemplatesGetter
 }
 
 // DwellerV1alpha1Client is used to interact with features provided by the dweller.io group.
@@ -33,10 +36,22 @@ type DwellerV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *DwellerV1alpha1Client) ClusterVaultSecretClaims() ClusterVaultSecretClaimInterface {
+	return newClusterVaultSecretClaims(c)
+}
+
+func (c *DwellerV1alpha1Client) VaultPathPolicies() VaultPathPolicyInterface {
+	return newVaultPathPolicies(c)
+}
+
 func (c *DwellerV1alpha1Client) VaultSecretClaims(namespace string) VaultSecretClaimInterface {
 	return newVaultSecretClaims(c, namespace)
 }
 
+func (c *DwellerV1alpha1Client) VaultSecretClaimTemplates() VaultSecretClaimTemplateInterface {
+	return newVaultSecretClaimTemplates(c)
+}
+
 // NewForConfig creates a new DwellerV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*DwellerV1alpha1Client, error) {
 	config := *c
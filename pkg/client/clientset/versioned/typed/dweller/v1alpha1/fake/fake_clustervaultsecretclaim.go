@@ -0,0 +1,129 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeClusterVaultSecretClaims implements ClusterVaultSecretClaimInterface
+type FakeClusterVaultSecretClaims struct {
+	Fake *FakeDwellerV1alpha1
+}
+
+var clustervaultsecretclaimsResource = schema.GroupVersionResource{Group: "dweller.io", Version: "v1alpha1", Resource: "clustervaultsecretclaims"}
+
+var clustervaultsecretclaimsKind = schema.GroupVersionKind{Group: "dweller.io", Version: "v1alpha1", Kind: "ClusterVaultSecretClaim"}
+
+// Get takes name of the clusterVaultSecretClaim, and returns the corresponding clusterVaultSecretClaim object, and an error if there is any.
+func (c *FakeClusterVaultSecretClaims) Get(name string, options v1.GetOptions) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(clustervaultsecretclaimsResource, name), &v1alpha1.ClusterVaultSecretClaim{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultSecretClaim), err
+}
+
+// List takes label and field selectors, and returns the list of ClusterVaultSecretClaims that match those selectors.
+func (c *FakeClusterVaultSecretClaims) List(opts v1.ListOptions) (result *v1alpha1.ClusterVaultSecretClaimList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(clustervaultsecretclaimsResource, clustervaultsecretclaimsKind, opts), &v1alpha1.ClusterVaultSecretClaimList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.ClusterVaultSecretClaimList{}
+	for _, item := range obj.(*v1alpha1.ClusterVaultSecretClaimList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested clusterVaultSecretClaims.
+func (c *FakeClusterVaultSecretClaims) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(clustervaultsecretclaimsResource, opts))
+}
+
+// Create takes the representation of a clusterVaultSecretClaim and creates it.  Returns the server's representation of the clusterVaultSecretClaim, and an error, if there is any.
+func (c *FakeClusterVaultSecretClaims) Create(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(clustervaultsecretclaimsResource, clusterVaultSecretClaim), &v1alpha1.ClusterVaultSecretClaim{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultSecretClaim), err
+}
+
+// Update takes the representation of a clusterVaultSecretClaim and updates it. Returns the server's representation of the clusterVaultSecretClaim, and an error, if there is any.
+func (c *FakeClusterVaultSecretClaims) Update(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(clustervaultsecretclaimsResource, clusterVaultSecretClaim), &v1alpha1.ClusterVaultSecretClaim{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultSecretClaim), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeClusterVaultSecretClaims) UpdateStatus(clusterVaultSecretClaim *v1alpha1.ClusterVaultSecretClaim) (*v1alpha1.ClusterVaultSecretClaim, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(clustervaultsecretclaimsResource, "status", clusterVaultSecretClaim), &v1alpha1.ClusterVaultSecretClaim{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultSecretClaim), err
+}
+
+// Delete takes name of the clusterVaultSecretClaim and deletes it. Returns an error if one occurs.
+func (c *FakeClusterVaultSecretClaims) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(clustervaultsecretclaimsResource, name), &v1alpha1.ClusterVaultSecretClaim{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeClusterVaultSecretClaims) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(clustervaultsecretclaimsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.ClusterVaultSecretClaimList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched clusterVaultSecretClaim.
+func (c *FakeClusterVaultSecretClaims) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.ClusterVaultSecretClaim, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(clustervaultsecretclaimsResource, name, data, subresources...), &v1alpha1.ClusterVaultSecretClaim{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ClusterVaultSecretClaim), err
+}
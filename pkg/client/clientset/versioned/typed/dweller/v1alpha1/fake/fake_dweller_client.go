@@ -26,10 +26,22 @@ type FakeDwellerV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeDwellerV1alpha1) ClusterVaultSecretClaims() v1alpha1.ClusterVaultSecretClaimInterface {
+	return &FakeClusterVaultSecretClaims{c}
+}
+
+func (c *FakeDwellerV1alpha1) VaultPathPolicies() v1alpha1.VaultPathPolicyInterface {
+	return &FakeVaultPathPolicies{c}
+}
+
 func (c *FakeDwellerV1alpha1) VaultSecretClaims(namespace string) v1alpha1.VaultSecretClaimInterface {
 	return &FakeVaultSecretClaims{c, namespace}
 }
 
+func (c *FakeDwellerV1alpha1) VaultSecretClaimTemplates() v1alpha1.VaultSecretClaimTemplateInterface {
+	return &FakeVaultSecretClaimTemplates{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeDwellerV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultPathPolicies implements VaultPathPolicyInterface
+type FakeVaultPathPolicies struct {
+	Fake *FakeDwellerV1alpha1
+}
+
+var vaultpathpoliciesResource = schema.GroupVersionResource{Group: "dweller.io", Version: "v1alpha1", Resource: "vaultpathpolicies"}
+
+var vaultpathpoliciesKind = schema.GroupVersionKind{Group: "dweller.io", Version: "v1alpha1", Kind: "VaultPathPolicy"}
+
+// Get takes name of the vaultPathPolicy, and returns the corresponding vaultPathPolicy object, and an error if there is any.
+func (c *FakeVaultPathPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultPathPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(vaultpathpoliciesResource, name), &v1alpha1.VaultPathPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPathPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of VaultPathPolicies that match those selectors.
+func (c *FakeVaultPathPolicies) List(opts v1.ListOptions) (result *v1alpha1.VaultPathPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(vaultpathpoliciesResource, vaultpathpoliciesKind, opts), &v1alpha1.VaultPathPolicyList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultPathPolicyList{}
+	for _, item := range obj.(*v1alpha1.VaultPathPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultPathPolicies.
+func (c *FakeVaultPathPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(vaultpathpoliciesResource, opts))
+}
+
+// Create takes the representation of a vaultPathPolicy and creates it.  Returns the server's representation of the vaultPathPolicy, and an error, if there is any.
+func (c *FakeVaultPathPolicies) Create(vaultPathPolicy *v1alpha1.VaultPathPolicy) (result *v1alpha1.VaultPathPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(vaultpathpoliciesResource, vaultPathPolicy), &v1alpha1.VaultPathPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPathPolicy), err
+}
+
+// Update takes the representation of a vaultPathPolicy and updates it. Returns the server's representation of the vaultPathPolicy, and an error, if there is any.
+func (c *FakeVaultPathPolicies) Update(vaultPathPolicy *v1alpha1.VaultPathPolicy) (result *v1alpha1.VaultPathPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(vaultpathpoliciesResource, vaultPathPolicy), &v1alpha1.VaultPathPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPathPolicy), err
+}
+
+// Delete takes name of the vaultPathPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeVaultPathPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(vaultpathpoliciesResource, name), &v1alpha1.VaultPathPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultPathPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(vaultpathpoliciesResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultPathPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultPathPolicy.
+func (c *FakeVaultPathPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPathPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(vaultpathpoliciesResource, name, data, subresources...), &v1alpha1.VaultPathPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPathPolicy), err
+}
@@ -98,6 +98,18 @@ func (c *FakeVaultSecretClaims) Update(vaultSecretClaim *v1alpha1.VaultSecretCla
 	return obj.(*v1alpha1.VaultSecretClaim), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultSecretClaims) UpdateStatus(vaultSecretClaim *v1alpha1.VaultSecretClaim) (*v1alpha1.VaultSecretClaim, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultsecretclaimsResource, "status", c.ns, vaultSecretClaim), &v1alpha1.VaultSecretClaim{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretClaim), err
+}
+
 // Delete takes name of the vaultSecretClaim and deletes it. Returns an error if one occurs.
 func (c *FakeVaultSecretClaims) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
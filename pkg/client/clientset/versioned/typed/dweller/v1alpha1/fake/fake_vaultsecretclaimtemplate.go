@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultSecretClaimTemplates implements VaultSecretClaimTemplateInterface
+type FakeVaultSecretClaimTemplates struct {
+	Fake *FakeDwellerV1alpha1
+}
+
+var vaultsecretclaimtemplatesResource = schema.GroupVersionResource{Group: "dweller.io", Version: "v1alpha1", Resource: "vaultsecretclaimtemplates"}
+
+var vaultsecretclaimtemplatesKind = schema.GroupVersionKind{Group: "dweller.io", Version: "v1alpha1", Kind: "VaultSecretClaimTemplate"}
+
+// Get takes name of the vaultSecretClaimTemplate, and returns the corresponding vaultSecretClaimTemplate object, and an error if there is any.
+func (c *FakeVaultSecretClaimTemplates) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultSecretClaimTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(vaultsecretclaimtemplatesResource, name), &v1alpha1.VaultSecretClaimTemplate{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretClaimTemplate), err
+}
+
+// List takes label and field selectors, and returns the list of VaultSecretClaimTemplates that match those selectors.
+func (c *FakeVaultSecretClaimTemplates) List(opts v1.ListOptions) (result *v1alpha1.VaultSecretClaimTemplateList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(vaultsecretclaimtemplatesResource, vaultsecretclaimtemplatesKind, opts), &v1alpha1.VaultSecretClaimTemplateList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultSecretClaimTemplateList{}
+	for _, item := range obj.(*v1alpha1.VaultSecretClaimTemplateList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultSecretClaimTemplates.
+func (c *FakeVaultSecretClaimTemplates) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(vaultsecretclaimtemplatesResource, opts))
+}
+
+// Create takes the representation of a vaultSecretClaimTemplate and creates it.  Returns the server's representation of the vaultSecretClaimTemplate, and an error, if there is any.
+func (c *FakeVaultSecretClaimTemplates) Create(vaultSecretClaimTemplate *v1alpha1.VaultSecretClaimTemplate) (result *v1alpha1.VaultSecretClaimTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(vaultsecretclaimtemplatesResource, vaultSecretClaimTemplate), &v1alpha1.VaultSecretClaimTemplate{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretClaimTemplate), err
+}
+
+// Update takes the representation of a vaultSecretClaimTemplate and updates it. Returns the server's representation of the vaultSecretClaimTemplate, and an error, if there is any.
+func (c *FakeVaultSecretClaimTemplates) Update(vaultSecretClaimTemplate *v1alpha1.VaultSecretClaimTemplate) (result *v1alpha1.VaultSecretClaimTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(vaultsecretclaimtemplatesResource, vaultSecretClaimTemplate), &v1alpha1.VaultSecretClaimTemplate{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretClaimTemplate), err
+}
+
+// Delete takes name of the vaultSecretClaimTemplate and deletes it. Returns an error if one occurs.
+func (c *FakeVaultSecretClaimTemplates) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(vaultsecretclaimtemplatesResource, name), &v1alpha1.VaultSecretClaimTemplate{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultSecretClaimTemplates) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(vaultsecretclaimtemplatesResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultSecretClaimTemplateList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultSecretClaimTemplate.
+func (c *FakeVaultSecretClaimTemplates) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultSecretClaimTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(vaultsecretclaimtemplatesResource, name, data, subresources...), &v1alpha1.VaultSecretClaimTemplate{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretClaimTemplate), err
+}
@@ -16,4 +16,10 @@ limitations under the License.
 
 package v1alpha1
 
+type ClusterVaultSecretClaimExpansion interface{}
+
+type VaultPathPolicyExpansion interface{}
+
 type VaultSecretClaimExpansion interface{}
+
+type VaultSecretClaimTemplateExpansion interface{}
@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	scheme "github.com/fukt/dweller/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultPathPoliciesGetter has a method to return a VaultPathPolicyInterface.
+// A group's client should implement this interface.
+type VaultPathPoliciesGetter interface {
+	VaultPathPolicies() VaultPathPolicyInterface
+}
+
+// VaultPathPolicyInterface has methods to work with VaultPathPolicy resources.
+type VaultPathPolicyInterface interface {
+	Create(*v1alpha1.VaultPathPolicy) (*v1alpha1.VaultPathPolicy, error)
+	Update(*v1alpha1.VaultPathPolicy) (*v1alpha1.VaultPathPolicy, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.VaultPathPolicy, error)
+	List(opts v1.ListOptions) (*v1alpha1.VaultPathPolicyList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPathPolicy, err error)
+	VaultPathPolicyExpansion
+}
+
+// vaultPathPolicies implements VaultPathPolicyInterface
+type vaultPathPolicies struct {
+	client rest.Interface
+}
+
+// newVaultPathPolicies returns a VaultPathPolicies
+func newVaultPathPolicies(c *DwellerV1alpha1Client) *vaultPathPolicies {
+	return &vaultPathPolicies{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the vaultPathPolicy, and returns the corresponding vaultPathPolicy object, and an error if there is any.
+func (c *vaultPathPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultPathPolicy, err error) {
+	result = &v1alpha1.VaultPathPolicy{}
+	err = c.client.Get().
+		Resource("vaultpathpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultPathPolicies that match those selectors.
+func (c *vaultPathPolicies) List(opts v1.ListOptions) (result *v1alpha1.VaultPathPolicyList, err error) {
+	result = &v1alpha1.VaultPathPolicyList{}
+	err = c.client.Get().
+		Resource("vaultpathpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultPathPolicies.
+func (c *vaultPathPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("vaultpathpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultPathPolicy and creates it.  Returns the server's representation of the vaultPathPolicy, and an error, if there is any.
+func (c *vaultPathPolicies) Create(vaultPathPolicy *v1alpha1.VaultPathPolicy) (result *v1alpha1.VaultPathPolicy, err error) {
+	result = &v1alpha1.VaultPathPolicy{}
+	err = c.client.Post().
+		Resource("vaultpathpolicies").
+		Body(vaultPathPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultPathPolicy and updates it. Returns the server's representation of the vaultPathPolicy, and an error, if there is any.
+func (c *vaultPathPolicies) Update(vaultPathPolicy *v1alpha1.VaultPathPolicy) (result *v1alpha1.VaultPathPolicy, err error) {
+	result = &v1alpha1.VaultPathPolicy{}
+	err = c.client.Put().
+		Resource("vaultpathpolicies").
+		Name(vaultPathPolicy.Name).
+		Body(vaultPathPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultPathPolicy and deletes it. Returns an error if one occurs.
+func (c *vaultPathPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("vaultpathpolicies").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultPathPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("vaultpathpolicies").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultPathPolicy.
+func (c *vaultPathPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPathPolicy, err error) {
+	result = &v1alpha1.VaultPathPolicy{}
+	err = c.client.Patch(pt).
+		Resource("vaultpathpolicies").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -35,6 +35,7 @@ type VaultSecretClaimsGetter interface {
 type VaultSecretClaimInterface interface {
 	Create(*v1alpha1.VaultSecretClaim) (*v1alpha1.VaultSecretClaim, error)
 	Update(*v1alpha1.VaultSecretClaim) (*v1alpha1.VaultSecretClaim, error)
+	UpdateStatus(*v1alpha1.VaultSecretClaim) (*v1alpha1.VaultSecretClaim, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.VaultSecretClaim, error)
@@ -118,6 +119,22 @@ func (c *vaultSecretClaims) Update(vaultSecretClaim *v1alpha1.VaultSecretClaim)
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultSecretClaims) UpdateStatus(vaultSecretClaim *v1alpha1.VaultSecretClaim) (result *v1alpha1.VaultSecretClaim, err error) {
+	result = &v1alpha1.VaultSecretClaim{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultsecretclaims").
+		Name(vaultSecretClaim.Name).
+		SubResource("status").
+		Body(vaultSecretClaim).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the vaultSecretClaim and deletes it. Returns an error if one occurs.
 func (c *vaultSecretClaims) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
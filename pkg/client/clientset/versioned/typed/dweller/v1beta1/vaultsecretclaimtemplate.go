@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
+	scheme "github.com/fukt/dweller/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultSecretClaimTemplatesGetter has a method to return a VaultSecretClaimTemplateInterface.
+// A group's client should implement this interface.
+type VaultSecretClaimTemplatesGetter interface {
+	VaultSecretClaimTemplates() VaultSecretClaimTemplateInterface
+}
+
+// VaultSecretClaimTemplateInterface has methods to work with VaultSecretClaimTemplate resources.
+type VaultSecretClaimTemplateInterface interface {
+	Create(*v1beta1.VaultSecretClaimTemplate) (*v1beta1.VaultSecretClaimTemplate, error)
+	Update(*v1beta1.VaultSecretClaimTemplate) (*v1beta1.VaultSecretClaimTemplate, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.VaultSecretClaimTemplate, error)
+	List(opts v1.ListOptions) (*v1beta1.VaultSecretClaimTemplateList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.VaultSecretClaimTemplate, err error)
+	VaultSecretClaimTemplateExpansion
+}
+
+// vaultSecretClaimTemplates implements VaultSecretClaimTemplateInterface
+type vaultSecretClaimTemplates struct {
+	client rest.Interface
+}
+
+// newVaultSecretClaimTemplates returns a VaultSecretClaimTemplates
+func newVaultSecretClaimTemplates(c *DwellerV1beta1Client) *vaultSecretClaimTemplates {
+	return &vaultSecretClaimTemplates{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the vaultSecretClaimTemplate, and returns the corresponding vaultSecretClaimTemplate object, and an error if there is any.
+func (c *vaultSecretClaimTemplates) Get(name string, options v1.GetOptions) (result *v1beta1.VaultSecretClaimTemplate, err error) {
+	result = &v1beta1.VaultSecretClaimTemplate{}
+	err = c.client.Get().
+		Resource("vaultsecretclaimtemplates").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultSecretClaimTemplates that match those selectors.
+func (c *vaultSecretClaimTemplates) List(opts v1.ListOptions) (result *v1beta1.VaultSecretClaimTemplateList, err error) {
+	result = &v1beta1.VaultSecretClaimTemplateList{}
+	err = c.client.Get().
+		Resource("vaultsecretclaimtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultSecretClaimTemplates.
+func (c *vaultSecretClaimTemplates) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Resource("vaultsecretclaimtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultSecretClaimTemplate and creates it.  Returns the server's representation of the vaultSecretClaimTemplate, and an error, if there is any.
+func (c *vaultSecretClaimTemplates) Create(vaultSecretClaimTemplate *v1beta1.VaultSecretClaimTemplate) (result *v1beta1.VaultSecretClaimTemplate, err error) {
+	result = &v1beta1.VaultSecretClaimTemplate{}
+	err = c.client.Post().
+		Resource("vaultsecretclaimtemplates").
+		Body(vaultSecretClaimTemplate).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultSecretClaimTemplate and updates it. Returns the server's representation of the vaultSecretClaimTemplate, and an error, if there is any.
+func (c *vaultSecretClaimTemplates) Update(vaultSecretClaimTemplate *v1beta1.VaultSecretClaimTemplate) (result *v1beta1.VaultSecretClaimTemplate, err error) {
+	result = &v1beta1.VaultSecretClaimTemplate{}
+	err = c.client.Put().
+		Resource("vaultsecretclaimtemplates").
+		Name(vaultSecretClaimTemplate.Name).
+		Body(vaultSecretClaimTemplate).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultSecretClaimTemplate and deletes it. Returns an error if one occurs.
+func (c *vaultSecretClaimTemplates) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("vaultsecretclaimtemplates").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultSecretClaimTemplates) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Resource("vaultsecretclaimtemplates").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultSecretClaimTemplate.
+func (c *vaultSecretClaimTemplates) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.VaultSecretClaimTemplate, err error) {
+	result = &v1beta1.VaultSecretClaimTemplate{}
+	err = c.client.Patch(pt).
+		Resource("vaultsecretclaimtemplates").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
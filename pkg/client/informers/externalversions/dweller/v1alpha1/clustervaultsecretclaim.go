@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1alpha1
+
+import (
+	time "time"
+
+	dweller_v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	versioned "github.com/fukt/dweller/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/fukt/dweller/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterVaultSecretClaimInformer provides access to a shared informer and lister for
+// ClusterVaultSecretClaims.
+type ClusterVaultSecretClaimInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.ClusterVaultSecretClaimLister
+}
+
+type clusterVaultSecretClaimInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterVaultSecretClaimInformer constructs a new informer for ClusterVaultSecretClaim type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterVaultSecretClaimInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterVaultSecretClaimInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterVaultSecretClaimInformer constructs a new informer for ClusterVaultSecretClaim type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterVaultSecretClaimInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1alpha1().ClusterVaultSecretClaims().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1alpha1().ClusterVaultSecretClaims().Watch(options)
+			},
+		},
+		&dweller_v1alpha1.ClusterVaultSecretClaim{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterVaultSecretClaimInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterVaultSecretClaimInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterVaultSecretClaimInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dweller_v1alpha1.ClusterVaultSecretClaim{}, f.defaultInformer)
+}
+
+func (f *clusterVaultSecretClaimInformer) Lister() v1alpha1.ClusterVaultSecretClaimLister {
+	return v1alpha1.NewClusterVaultSecretClaimLister(f.Informer().GetIndexer())
+}
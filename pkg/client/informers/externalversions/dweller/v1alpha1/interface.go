@@ -24,8 +24,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// ClusterVaultSecretClaims returns a ClusterVaultSecretClaimInformer.
+	ClusterVaultSecretClaims() ClusterVaultSecretClaimInformer
+	// VaultPathPolicies returns a VaultPathPolicyInformer.
+	VaultPathPolicies() VaultPathPolicyInformer
 	// VaultSecretClaims returns a VaultSecretClaimInformer.
 	VaultSecretClaims() VaultSecretClaimInformer
+	// VaultSecretClaimTemplates returns a VaultSecretClaimTemplateInformer.
+	VaultSecretClaimTemplates() VaultSecretClaimTemplateInformer
 }
 
 type version struct {
@@ -39,7 +45,22 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// ClusterVaultSecretClaims returns a ClusterVaultSecretClaimInformer.
+func (v *version) ClusterVaultSecretClaims() ClusterVaultSecretClaimInformer {
+	return &clusterVaultSecretClaimInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// VaultPathPolicies returns a VaultPathPolicyInformer.
+func (v *version) VaultPathPolicies() VaultPathPolicyInformer {
+	return &vaultPathPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // VaultSecretClaims returns a VaultSecretClaimInformer.
 func (v *version) VaultSecretClaims() VaultSecretClaimInformer {
 	return &vaultSecretClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// VaultSecretClaimTemplates returns a VaultSecretClaimTemplateInformer.
+func (v *version) VaultSecretClaimTemplates() VaultSecretClaimTemplateInformer {
+	return &vaultSecretClaimTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1alpha1
+
+import (
+	time "time"
+
+	dweller_v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	versioned "github.com/fukt/dweller/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/fukt/dweller/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VaultPathPolicyInformer provides access to a shared informer and lister for
+// VaultPathPolicies.
+type VaultPathPolicyInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.VaultPathPolicyLister
+}
+
+type vaultPathPolicyInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewVaultPathPolicyInformer constructs a new informer for VaultPathPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVaultPathPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredVaultPathPolicyInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredVaultPathPolicyInformer constructs a new informer for VaultPathPolicy type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredVaultPathPolicyInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1alpha1().VaultPathPolicies().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1alpha1().VaultPathPolicies().Watch(options)
+			},
+		},
+		&dweller_v1alpha1.VaultPathPolicy{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *vaultPathPolicyInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredVaultPathPolicyInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *vaultPathPolicyInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dweller_v1alpha1.VaultPathPolicy{}, f.defaultInformer)
+}
+
+func (f *vaultPathPolicyInformer) Lister() v1alpha1.VaultPathPolicyLister {
+	return v1alpha1.NewVaultPathPolicyLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1beta1
+
+import (
+	internalinterfaces "github.com/fukt/dweller/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// VaultSecretClaims returns a VaultSecretClaimInformer.
+	VaultSecretClaims() VaultSecretClaimInformer
+	// VaultSecretClaimTemplates returns a VaultSecretClaimTemplateInformer.
+	VaultSecretClaimTemplates() VaultSecretClaimTemplateInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// VaultSecretClaims returns a VaultSecretClaimInformer.
+func (v *version) VaultSecretClaims() VaultSecretClaimInformer {
+	return &vaultSecretClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// VaultSecretClaimTemplates returns a VaultSecretClaimTemplateInformer.
+func (v *version) VaultSecretClaimTemplates() VaultSecretClaimTemplateInformer {
+	return &vaultSecretClaimTemplateInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was automatically generated by informer-gen
+
+package v1beta1
+
+import (
+	time "time"
+
+	dweller_v1beta1 "github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
+	versioned "github.com/fukt/dweller/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/fukt/dweller/pkg/client/informers/externalversions/internalinterfaces"
+	v1beta1 "github.com/fukt/dweller/pkg/client/listers/dweller/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// VaultSecretClaimTemplateInformer provides access to a shared informer and lister for
+// VaultSecretClaimTemplates.
+type VaultSecretClaimTemplateInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.VaultSecretClaimTemplateLister
+}
+
+type vaultSecretClaimTemplateInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewVaultSecretClaimTemplateInformer constructs a new informer for VaultSecretClaimTemplate type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVaultSecretClaimTemplateInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredVaultSecretClaimTemplateInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredVaultSecretClaimTemplateInformer constructs a new informer for VaultSecretClaimTemplate type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredVaultSecretClaimTemplateInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1beta1().VaultSecretClaimTemplates().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.DwellerV1beta1().VaultSecretClaimTemplates().Watch(options)
+			},
+		},
+		&dweller_v1beta1.VaultSecretClaimTemplate{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *vaultSecretClaimTemplateInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredVaultSecretClaimTemplateInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *vaultSecretClaimTemplateInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&dweller_v1beta1.VaultSecretClaimTemplate{}, f.defaultInformer)
+}
+
+func (f *vaultSecretClaimTemplateInformer) Lister() v1beta1.VaultSecretClaimTemplateLister {
+	return v1beta1.NewVaultSecretClaimTemplateLister(f.Informer().GetIndexer())
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	v1beta1 "github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -53,8 +54,20 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=dweller.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("clustervaultsecretclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1alpha1().ClusterVaultSecretClaims().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultpathpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1alpha1().VaultPathPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("vaultsecretclaims"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1alpha1().VaultSecretClaims().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultsecretclaimtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1alpha1().VaultSecretClaimTemplates().Informer()}, nil
+
+		// Group=dweller.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("vaultsecretclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1beta1().VaultSecretClaims().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("vaultsecretclaimtemplates"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Dweller().V1beta1().VaultSecretClaimTemplates().Informer()}, nil
 
 	}
 
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// ClusterVaultSecretClaimListerExpansion allows custom methods to be added to
+// ClusterVaultSecretClaimLister.
+type ClusterVaultSecretClaimListerExpansion interface{}
+
+// VaultPathPolicyListerExpansion allows custom methods to be added to
+// VaultPathPolicyLister.
+type VaultPathPolicyListerExpansion interface{}
+
 // VaultSecretClaimListerExpansion allows custom methods to be added to
 // VaultSecretClaimLister.
 type VaultSecretClaimListerExpansion interface{}
@@ -25,3 +33,7 @@ type VaultSecretClaimListerExpansion interface{}
 // VaultSecretClaimNamespaceListerExpansion allows custom methods to be added to
 // VaultSecretClaimNamespaceLister.
 type VaultSecretClaimNamespaceListerExpansion interface{}
+
+// VaultSecretClaimTemplateListerExpansion allows custom methods to be added to
+// VaultSecretClaimTemplateLister.
+type VaultSecretClaimTemplateListerExpansion interface{}
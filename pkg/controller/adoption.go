@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+const (
+	// adoptLabel marks a secret as adoptable by vault secret claim with the
+	// name set as the label value. It is used by IfLabelled adoption policy.
+	adoptLabel = "dweller.io/adopt"
+
+	// backupLabel is set on backups of adopted secrets to the name of the
+	// adopted secret.
+	backupLabel = "dweller.io/backup-of"
+)
+
+// canAdopt reports whether vault secret claim is allowed to adopt the secret.
+// Secrets controlled by someone else are never adopted.
+func canAdopt(vsc *v1alpha1.VaultSecretClaim, sec *corev1.Secret) bool {
+	if metav1.GetControllerOf(sec) != nil {
+		return false
+	}
+
+	switch vsc.Spec.AdoptionPolicy {
+	case v1alpha1.AdoptAlways:
+		return true
+	case v1alpha1.AdoptIfLabelled:
+		return sec.Labels[adoptLabel] == vsc.Name
+	default:
+		return false
+	}
+}
+
+// adoptSecrets takes ownership of the secrets adding vault secret claim
+// controller reference to them. Adopted secrets are added to the owned ones
+// and the adoptions are recorded in the claim status.
+func (c *Controller) adoptSecrets(vsc *v1alpha1.VaultSecretClaim, secrets []*corev1.Secret, owned map[string]*corev1.Secret) error {
+	status := vsc.Status.DeepCopy()
+
+	for _, sec := range secrets {
+		adoption := v1alpha1.Adoption{
+			Secret: sec.Name,
+			Time:   metav1.Now(),
+		}
+
+		if vsc.Spec.BackupOnAdoption {
+			backup, err := c.backupSecret(sec)
+			if err != nil {
+				return err
+			}
+			adoption.Backup = backup
+		}
+
+		// Deep-copy otherwise we are mutating our cache.
+		adopted := sec.DeepCopy()
+		ownerRef := metav1.NewControllerRef(vsc, v1alpha1.SchemeGroupVersionKind)
+		adopted.OwnerReferences = append(adopted.OwnerReferences, *ownerRef)
+
+		adopted, err := c.client.CoreV1().Secrets(adopted.Namespace).Update(adopted)
+		if err != nil {
+			return fmt.Errorf("adopt kubernetes secret: %v", err)
+		}
+		c.logger.Infof("Secret \"%s/%s\" has been adopted by VaultSecretClaim \"%s/%s\"", sec.Namespace, sec.Name, vsc.Namespace, vsc.Name)
+
+		owned[adopted.Name] = adopted
+		status.Adoptions = append(status.Adoptions, adoption)
+	}
+
+	// Record adoptions right away, they won't be seen again.
+	return c.updateStatus(vsc, *status)
+}
+
+// backupSecret copies the secret content to a new secret that is not owned by
+// anyone and returns its name. Backups of equal content share the same name.
+func (c *Controller) backupSecret(sec *corev1.Secret) (string, error) {
+	backup := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-backup-%s", sec.Name, secret.Hash(sec)),
+			Namespace:   sec.Namespace,
+			Labels:      map[string]string{backupLabel: sec.Name},
+			Annotations: sec.Annotations,
+		},
+		Type: sec.Type,
+		Data: sec.Data,
+	}
+
+	_, err := c.client.CoreV1().Secrets(backup.Namespace).Create(backup)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("back up kubernetes secret: %v", err)
+	}
+	c.logger.Infof("Secret \"%s/%s\" has been backed up to \"%s\"", sec.Namespace, sec.Name, backup.Name)
+
+	return backup.Name, nil
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// appsVersions are versions of the apps API group workloads are listed and
+// patched with, the most preferred first. apps/v1 is served since Kubernetes
+// 1.9, apps/v1beta2 is removed in 1.16.
+var appsVersions = []string{"v1", "v1beta2"}
+
+// appsClient lists and patches workloads. The client we use does not ship
+// apps/v1, so workloads are accessed through a REST client of the most
+// preferred version served by the cluster. Only metadata of workloads is used,
+// and it's the same in every version.
+type appsClient struct {
+	config    *rest.Config
+	discovery discovery.DiscoveryInterface
+
+	mu      sync.Mutex
+	version string
+	client  rest.Interface
+}
+
+func newAppsClient(config *rest.Config, discovery discovery.DiscoveryInterface) *appsClient {
+	return &appsClient{config: config, discovery: discovery}
+}
+
+// get returns the REST client, discovering the version on the first call.
+func (ac *appsClient) get() (rest.Interface, string, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.client != nil {
+		return ac.client, ac.version, nil
+	}
+
+	groups, err := ac.discovery.ServerGroups()
+	if err != nil {
+		return nil, "", fmt.Errorf("discover API groups: %v", err)
+	}
+	version, err := preferredAppsVersion(groups)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := newAppsRESTClient(ac.config, version)
+	if err != nil {
+		return nil, "", fmt.Errorf("create apps/%s client: %v", version, err)
+	}
+
+	ac.client, ac.version = client, version
+	return client, version, nil
+}
+
+// reset makes the version to be discovered again, e.g. after the cluster has
+// stopped serving it.
+func (ac *appsClient) reset() {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.client, ac.version = nil, ""
+}
+
+// workloadList is a list of workloads of any kind with metadata only.
+type workloadList struct {
+	Items []struct {
+		metav1.ObjectMeta `json:"metadata"`
+	} `json:"items"`
+}
+
+// list returns metadata of workloads of the resource in the namespace.
+func (ac *appsClient) list(resource, namespace string) ([]metav1.ObjectMeta, error) {
+	client, _, err := ac.get()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.Get().Namespace(namespace).Resource(resource).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var list workloadList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", resource, err)
+	}
+	items := make([]metav1.ObjectMeta, 0, len(list.Items))
+	for _, item := range list.Items {
+		items = append(items, item.ObjectMeta)
+	}
+	return items, nil
+}
+
+// patch applies the strategic merge patch to the workload and returns the
+// reference to it.
+func (ac *appsClient) patch(w workload, resource string, patch []byte) (*corev1.ObjectReference, error) {
+	client, version, err := ac.get()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.Patch(types.StrategicMergePatchType).Namespace(w.namespace).Resource(resource).Name(w.name).Body(patch).Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var patched struct {
+		metav1.ObjectMeta `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &patched); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", w.kind, err)
+	}
+	return &corev1.ObjectReference{
+		APIVersion:      "apps/" + version,
+		Kind:            w.kind,
+		Namespace:       patched.Namespace,
+		Name:            patched.Name,
+		UID:             patched.UID,
+		ResourceVersion: patched.ResourceVersion,
+	}, nil
+}
+
+// preferredAppsVersion returns the most preferred version of the apps API
+// group among the served ones.
+func preferredAppsVersion(groups *metav1.APIGroupList) (string, error) {
+	for _, group := range groups.Groups {
+		if group.Name != "apps" {
+			continue
+		}
+		for _, version := range appsVersions {
+			for _, served := range group.Versions {
+				if served.Version == version {
+					return version, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("none of apps API versions %v is served", appsVersions)
+}
+
+func newAppsRESTClient(config *rest.Config, version string) (*rest.RESTClient, error) {
+	cfg := *config
+	cfg.GroupVersion = &schema.GroupVersion{Group: "apps", Version: version}
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+	return rest.RESTClientFor(&cfg)
+}
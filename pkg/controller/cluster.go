@@ -0,0 +1,553 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// clusterClaimKind is kind of cluster vault secret claim.
+const clusterClaimKind = "ClusterVaultSecretClaim"
+
+// Reasons of cluster vault secret claim conditions and namespace statuses.
+const (
+	reasonNamespacesNotReady = "NamespacesNotReady"
+	reasonWriteFailed        = "WriteFailed"
+)
+
+// WithClusterClaims makes the controller sync cluster vault secret claims,
+// which write their secret to every selected namespace. It requires
+// permissions to list and watch namespaces and cluster vault secret claims.
+func WithClusterClaims() Option {
+	return func(c *Controller) {
+		c.clusterClaims = true
+	}
+}
+
+// setUpClusterClaims sets up informers of cluster vault secret claims and of
+// namespaces they select.
+func (c *Controller) setUpClusterClaims() {
+	c.clusterQueue = workqueue.NewRateLimitingQueue(c.retry.rateLimiter())
+
+	informer := c.customFactory.Dweller().V1alpha1().ClusterVaultSecretClaims().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addClusterVaultSecretClaim,
+		UpdateFunc: c.updateClusterVaultSecretClaim,
+		DeleteFunc: c.deleteClusterVaultSecretClaim,
+	})
+	if err := informer.AddIndexers(cache.Indexers{targetIndex: indexClusterClaimByTarget}); err != nil {
+		// Can't happen: the informer is not started yet.
+		c.logger.Errorf("Couldn't index ClusterVaultSecretClaims: %v", err)
+	}
+	c.clusterClaimLister = c.customFactory.Dweller().V1alpha1().ClusterVaultSecretClaims().Lister()
+	c.clusterClaimIndexer = informer.GetIndexer()
+
+	// Secrets are written to watched namespaces only, but all the namespaces
+	// are listed to match their labels against the claims.
+	c.clusterNamespaceInformer = c.newSelectedNamespaceInformer(labels.Everything())
+	c.clusterNamespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addClusterNamespace,
+		UpdateFunc: c.updateClusterNamespace,
+		DeleteFunc: c.deleteClusterNamespace,
+	})
+	c.namespaceLister = corelisters.NewNamespaceLister(c.clusterNamespaceInformer.GetIndexer())
+}
+
+func (c *Controller) addClusterVaultSecretClaim(obj interface{}) {
+	cvsc := obj.(*v1alpha1.ClusterVaultSecretClaim)
+	c.logger.Infof("Adding ClusterVaultSecretClaim %q", cvsc.Name)
+	c.clusterQueue.Add(cvsc.Name)
+}
+
+func (c *Controller) updateClusterVaultSecretClaim(old, new interface{}) {
+	oldCvsc := old.(*v1alpha1.ClusterVaultSecretClaim)
+	newCvsc := new.(*v1alpha1.ClusterVaultSecretClaim)
+	if reflect.DeepEqual(oldCvsc.Spec, newCvsc.Spec) && !reflect.DeepEqual(oldCvsc.Status, newCvsc.Status) {
+		// Status is written by the controller itself on every sync.
+		return
+	}
+	c.logger.Infof("Updating ClusterVaultSecretClaim %q", newCvsc.Name)
+	c.clusterQueue.Add(newCvsc.Name)
+}
+
+func (c *Controller) deleteClusterVaultSecretClaim(obj interface{}) {
+	cvsc, ok := obj.(*v1alpha1.ClusterVaultSecretClaim)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.Errorf("Couldn't get object from tombstone %#v", obj)
+			return
+		}
+		cvsc, ok = tombstone.Obj.(*v1alpha1.ClusterVaultSecretClaim)
+		if !ok {
+			c.logger.Errorf("Tombstone contained object that is not a ClusterVaultSecretClaim %#v", obj)
+			return
+		}
+	}
+	c.logger.Infof("Deleting ClusterVaultSecretClaim %q", cvsc.Name)
+	c.clusterQueue.Add(cvsc.Name)
+}
+
+func (c *Controller) addClusterNamespace(obj interface{}) {
+	c.enqueueNamespaceClusterClaims(obj.(*corev1.Namespace))
+}
+
+func (c *Controller) updateClusterNamespace(old, new interface{}) {
+	oldNs := old.(*corev1.Namespace)
+	newNs := new.(*corev1.Namespace)
+	if reflect.DeepEqual(oldNs.Labels, newNs.Labels) && oldNs.Status.Phase == newNs.Status.Phase {
+		return
+	}
+
+	// Claims that selected the namespace before the change are notified as
+	// well to remove their secrets.
+	c.enqueueNamespaceClusterClaims(oldNs)
+	c.enqueueNamespaceClusterClaims(newNs)
+}
+
+func (c *Controller) deleteClusterNamespace(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		c.logger.Errorf("Couldn't get Namespace from %#v", obj)
+		return
+	}
+	c.enqueueNamespaceClusterClaims(ns)
+}
+
+// enqueueNamespaceClusterClaims adds cluster vault secret claims selecting the
+// namespace or having written their secret to it to the queue.
+func (c *Controller) enqueueNamespaceClusterClaims(ns *corev1.Namespace) {
+	claims, err := c.clusterClaimLister.List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Couldn't list ClusterVaultSecretClaims: %v", err)
+		return
+	}
+
+	for _, cvsc := range claims {
+		selector, err := metav1.LabelSelectorAsSelector(&cvsc.Spec.NamespaceSelector)
+		selected := err == nil && selector.Matches(labels.Set(ns.Labels))
+		if selected || namespaceStatus(&cvsc.Status, ns.Name) != nil {
+			c.logger.Debugf("Namespace %q of ClusterVaultSecretClaim %q has changed", ns.Name, cvsc.Name)
+			c.clusterQueue.Add(cvsc.Name)
+		}
+	}
+}
+
+// enqueueConflictingClusterClaims adds cluster vault secret claims writing
+// the secret that are not ready in its namespace due to a conflict to the
+// queue.
+func (c *Controller) enqueueConflictingClusterClaims(sec *corev1.Secret) {
+	if c.clusterQueue == nil {
+		return
+	}
+
+	objs, err := c.clusterClaimIndexer.ByIndex(targetIndex, sec.Name)
+	if err != nil {
+		c.logger.Errorf("Couldn't get ClusterVaultSecretClaims of Secret %q: %v", sec.Name, err)
+		return
+	}
+
+	for _, obj := range objs {
+		cvsc := obj.(*v1alpha1.ClusterVaultSecretClaim)
+		status := namespaceStatus(&cvsc.Status, sec.Namespace)
+		if status != nil && status.Reason == reasonConflict {
+			c.clusterQueue.Add(cvsc.Name)
+		}
+	}
+}
+
+// namespaceStatus returns the status of the namespace or nil if there is no
+// such namespace.
+func namespaceStatus(status *v1alpha1.ClusterVaultSecretClaimStatus, namespace string) *v1alpha1.NamespaceStatus {
+	for i := range status.Namespaces {
+		if status.Namespaces[i].Namespace == namespace {
+			return &status.Namespaces[i]
+		}
+	}
+	return nil
+}
+
+func (c *Controller) runClusterWorker() {
+	for c.waitHealthy() && c.processNextClusterClaim() {
+		// continue looping
+	}
+}
+
+func (c *Controller) processNextClusterClaim() bool {
+	key, quit := c.clusterQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.clusterQueue.Done(key)
+
+	err := c.syncClusterVaultSecretClaim(key.(string))
+	c.handleClusterProcessingError(err, key.(string))
+
+	return true
+}
+
+// handleClusterProcessingError retries the failed sync of cluster vault secret
+// claim. Conflicts are not errors of the sync, they are reported per
+// namespace. Unlike vault secret claims, cluster claims are not parked, but
+// retried on the parked interval after too many retries.
+func (c *Controller) handleClusterProcessingError(err error, key string) {
+	if err == nil {
+		c.clusterQueue.Forget(key)
+		return
+	}
+
+	if err == secret.ErrUnavailable || !c.health.Healthy() {
+		// Workers are paused until the secret provider recovers.
+		c.logger.Errorf("Error processing ClusterVaultSecretClaim %q (will retry once secret provider recovers): %v", key, err)
+		c.recordClusterSyncError(key, err, reasonParked)
+		c.clusterQueue.Forget(key)
+		c.clusterQueue.Add(key)
+		return
+	}
+
+	if secret.ClassOf(err) == secret.ClassInvalidSpec {
+		c.logger.Warnf("Error processing ClusterVaultSecretClaim %q (waiting for the spec to be fixed): %v", key, err)
+		c.recordClusterSyncError(key, err, reasonInvalidSpec)
+		if cvsc, getErr := c.clusterClaimLister.Get(key); getErr == nil {
+			c.event(cvsc, corev1.EventTypeWarning, eventInvalidSpec, "", "%v", err)
+		}
+		c.clusterQueue.Forget(key)
+		return
+	}
+
+	if c.clusterQueue.NumRequeues(key) < c.retry.MaxRetries {
+		c.logger.Errorf("Error processing ClusterVaultSecretClaim %q (will retry): %v", key, err)
+		c.recordClusterSyncError(key, err, reasonSyncFailed)
+		c.clusterQueue.AddRateLimited(key)
+		return
+	}
+
+	c.logger.Errorf("Error processing ClusterVaultSecretClaim %q (will retry in %v): %v", key, c.retry.ParkedInterval, err)
+	c.recordClusterSyncError(key, err, reasonParked)
+	c.clusterQueue.Forget(key)
+	c.clusterQueue.AddAfter(key, c.retry.ParkedInterval)
+}
+
+// syncClusterVaultSecretClaim syncs the cluster vault secret claim with the
+// given name. The secret is assembled once and written to every selected
+// namespace watched by the controller. Secrets of namespaces that are not
+// selected anymore are deleted.
+func (c *Controller) syncClusterVaultSecretClaim(name string) error {
+	startTime := time.Now()
+	c.logger.Infof("Started syncing ClusterVaultSecretClaim %q (%v)", name, startTime.Format(time.RFC3339Nano))
+	defer func() {
+		c.logger.Infof("Finished syncing ClusterVaultSecretClaim %q (%v)", name, time.Since(startTime))
+	}()
+
+	claim, err := c.clusterClaimLister.Get(name)
+	if apierrors.IsNotFound(err) {
+		// Secrets are garbage collected along with the claim.
+		c.logger.Infof("ClusterVaultSecretClaim %q has been deleted", name)
+		c.events.forget(name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Deep-copy otherwise we are mutating our cache.
+	cvsc := claim.DeepCopy()
+
+	if errs := validation.ValidateClusterVaultSecretClaim(cvsc); len(errs) > 0 {
+		return secret.NewError(secret.ClassInvalidSpec, errs.ToAggregate())
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&cvsc.Spec.NamespaceSelector)
+	if err != nil {
+		return secret.NewError(secret.ClassInvalidSpec, err)
+	}
+
+	namespaces, err := c.namespaceLister.List(selector)
+	if err != nil {
+		return err
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+
+	owned, err := c.clusterOwnedSecrets(cvsc)
+	if err != nil {
+		return err
+	}
+
+	// Secrets are written only to namespaces vault path policies allow to
+	// read every path of the claim, so the paths are checked before Vault
+	// is read, and Vault is not read at all if no namespace is allowed.
+	var (
+		targets []*corev1.Namespace
+		denied  = make(map[string]*secret.ItemError)
+	)
+	for _, ns := range namespaces {
+		if !c.namespaces.watched(ns.Name) || ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		targets = append(targets, ns)
+		if itemErr := c.authorizeClusterNamespace(cvsc, ns.Name); itemErr != nil {
+			denied[ns.Name] = itemErr
+		}
+	}
+
+	var (
+		assembled corev1.Secret
+		skipped   []v1alpha1.SkippedItem
+	)
+	if c.pathAuthorizer == nil || len(denied) < len(targets) {
+		// The secret is the same in every namespace, so Vault is read once.
+		vsc := &v1alpha1.VaultSecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: cvsc.Name, UID: cvsc.UID},
+			Spec: v1alpha1.VaultSecretClaimSpec{
+				Secret:        cvsc.Spec.Secret,
+				FailurePolicy: cvsc.Spec.FailurePolicy,
+			},
+		}
+		assembled, skipped, err = c.asm.Assemble(vsc, &cvsc.Spec.Secret)
+		if err != nil {
+			return err
+		}
+		assembled.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(cvsc, v1alpha1.SchemeGroupVersion.WithKind(clusterClaimKind)),
+		}
+		if cvsc.Spec.Type != "" {
+			assembled.Type = cvsc.Spec.Type
+		}
+	}
+
+	var (
+		statuses []v1alpha1.NamespaceStatus
+		written  = make(map[string]bool)
+		notReady int
+		errs     []error
+	)
+	for _, ns := range targets {
+		if itemErr := denied[ns.Name]; itemErr != nil {
+			// The rest of the namespaces are synced anyway. A secret written
+			// before is left in place, like secrets of denied claims are.
+			for key, sec := range owned {
+				if sec.Namespace == ns.Name {
+					written[key] = true
+				}
+			}
+			status := v1alpha1.NamespaceStatus{Namespace: ns.Name, Ready: corev1.ConditionFalse, Message: itemErr.Error()}
+			if _, isDenied := itemErr.Err.(*secret.PolicyDeniedError); isDenied {
+				status.Reason = reasonPolicyDenied
+				c.event(cvsc, corev1.EventTypeWarning, eventPolicyDenied, ns.Name, "Secret is not written to namespace %q: %v", ns.Name, itemErr)
+			} else {
+				status.Reason = reasonSyncFailed
+				errs = append(errs, itemErr)
+			}
+			notReady++
+			statuses = append(statuses, status)
+			continue
+		}
+
+		sec := assembled.DeepCopy()
+		sec.Namespace = ns.Name
+		related := owned[ns.Name+"/"+sec.Name]
+		written[ns.Name+"/"+sec.Name] = true
+
+		status, err := c.syncClusterSecret(cvsc, related, sec, skipped)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if status.Ready != corev1.ConditionTrue {
+			notReady++
+		}
+		statuses = append(statuses, status)
+	}
+
+	for key, sec := range owned {
+		if written[key] {
+			continue
+		}
+		err := c.client.CoreV1().Secrets(sec.Namespace).Delete(sec.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("delete kubernetes secret: %v", err))
+			continue
+		}
+		c.logger.Infof("Secret \"%s/%s\" of ClusterVaultSecretClaim %q has been deleted", sec.Namespace, sec.Name, cvsc.Name)
+	}
+
+	status := cvsc.Status.DeepCopy()
+	status.ObservedGeneration = cvsc.Generation
+	status.SkippedItems = skipped
+	status.Namespaces = statuses
+	syncErr := utilerrors.NewAggregate(errs)
+	if syncErr == nil {
+		setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionTrue, reasonSynced, "")
+	} else {
+		setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionFalse, reasonSyncFailed, syncErr.Error())
+	}
+	if notReady == 0 {
+		setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionTrue, reasonSecretsReady, "")
+	} else {
+		setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionFalse, reasonNamespacesNotReady,
+			fmt.Sprintf("Secret is not ready in %d of %d namespaces", notReady, len(statuses)))
+	}
+	if syncErr == nil {
+		status.LastSyncTime = lastSyncTime(status.LastSyncTime, !reflect.DeepEqual(cvsc.Status, *status))
+	}
+	if err := c.updateClusterStatus(cvsc, *status); err != nil {
+		return err
+	}
+	if syncErr == nil {
+		c.events.forgetWarnings(name)
+	}
+
+	return syncErr
+}
+
+// syncClusterSecret writes the secret of cluster vault secret claim to its
+// namespace and returns the status of the namespace. Existing secrets that are
+// not owned by the claim are reported as conflicts and left alone.
+func (c *Controller) syncClusterSecret(cvsc *v1alpha1.ClusterVaultSecretClaim, relatedSecret, sec *corev1.Secret, skipped []v1alpha1.SkippedItem) (v1alpha1.NamespaceStatus, error) {
+	status := v1alpha1.NamespaceStatus{Namespace: sec.Namespace, Ready: corev1.ConditionFalse}
+
+	if relatedSecret == nil {
+		existing, err := c.secretLister.Secrets(sec.Namespace).Get(sec.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			status.Reason = reasonWriteFailed
+			status.Message = err.Error()
+			return status, err
+		}
+		if err == nil {
+			conflict := fmt.Errorf("Conflict: found secret \"%s/%s\" that is not owned by cluster vault secret claim. This must be resolved manually.", existing.Namespace, existing.Name)
+			c.logger.Warnf("%v", conflict)
+			c.event(cvsc, corev1.EventTypeWarning, eventConflict, sec.Namespace, "%v", conflict)
+			status.Reason = reasonConflict
+			status.Message = conflict.Error()
+			return status, nil
+		}
+	}
+
+	if cvsc.Spec.FailurePolicy == v1alpha1.KeepLastKnown {
+		// Reasons of skipped items are reported once for all the namespaces.
+		ignored := append([]v1alpha1.SkippedItem(nil), skipped...)
+		keepLastKnown(ignored, lastKnownValues(relatedSecret, nil), sec.StringData)
+	}
+
+	hash := contentHash(sec)
+	status.Hash = hash
+	setContentHash(sec, hash)
+
+	if relatedSecret != nil && relatedSecret.Type != sec.Type {
+		// Type of a secret can't be updated, so the secret is written anew.
+		err := c.client.CoreV1().Secrets(sec.Namespace).Delete(sec.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			status.Reason = reasonWriteFailed
+			status.Message = err.Error()
+			return status, fmt.Errorf("delete kubernetes secret: %v", err)
+		}
+		relatedSecret = nil
+	}
+
+	var err error
+	switch {
+	case relatedSecret == nil:
+		if err = c.createSecret(sec); err == nil {
+			c.logger.Infof("Secret \"%s/%s\" has been created", sec.Namespace, sec.Name)
+			c.event(cvsc, corev1.EventTypeNormal, eventSecretCreated, sec.Namespace, "Secret %q has been created in namespace %q", sec.Name, sec.Namespace)
+		}
+	case isUpToDate(relatedSecret, hash):
+		metrics.Add(metricSecretWritesSkipped, 1)
+	default:
+		// Deep-copy otherwise we are mutating our cache.
+		if err = c.updateSecret(relatedSecret.DeepCopy(), sec); err == nil {
+			c.logger.Infof("Secret \"%s/%s\" has been updated", sec.Namespace, sec.Name)
+			c.event(cvsc, corev1.EventTypeNormal, eventSecretUpdated, sec.Namespace, "Secret %q has been updated in namespace %q to content %s", sec.Name, sec.Namespace, hash)
+		}
+	}
+	if err != nil {
+		status.Reason = reasonWriteFailed
+		status.Message = err.Error()
+		return status, err
+	}
+
+	status.Ready = corev1.ConditionTrue
+	return status, nil
+}
+
+// clusterOwnedSecrets returns all the secrets controlled by cluster vault
+// secret claim by their keys.
+func (c *Controller) clusterOwnedSecrets(cvsc *v1alpha1.ClusterVaultSecretClaim) (map[string]*corev1.Secret, error) {
+	objs, err := c.secretIndexer.ByIndex(ownerIndex, string(cvsc.UID))
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]*corev1.Secret, len(objs))
+	for _, obj := range objs {
+		sec := obj.(*corev1.Secret)
+		owned[sec.Namespace+"/"+sec.Name] = sec
+	}
+	return owned, nil
+}
+
+// recordClusterSyncError stores the error of the last sync of cluster vault
+// secret claim in its status with the given reason.
+func (c *Controller) recordClusterSyncError(name string, syncErr error, reason string) {
+	cvsc, err := c.clusterClaimLister.Get(name)
+	if err != nil {
+		// Nowhere to record the error.
+		return
+	}
+
+	status := cvsc.Status.DeepCopy()
+	status.ObservedGeneration = cvsc.Generation
+	if itemErrs, ok := syncErr.(secret.ItemErrors); ok {
+		for _, itemErr := range itemErrs {
+			reason := eventVaultReadFailed
+			if itemErr.Class() == secret.ClassPermission {
+				reason = eventPermissionDenied
+			}
+			c.event(cvsc, corev1.EventTypeWarning, reason, itemErr.Key, "%v", itemErr)
+		}
+	}
+	setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionFalse, reason, syncErr.Error())
+	if getCondition(status.Conditions, v1alpha1.ClaimReady) == nil {
+		setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionFalse, reason, "Secrets have not been synced yet")
+	}
+
+	// Deep-copy otherwise we are mutating our cache.
+	if err := c.updateClusterStatus(cvsc.DeepCopy(), *status); err != nil {
+		c.logger.Errorf("%v", err)
+	}
+}
+
+// updateClusterStatus stores the status of cluster vault secret claim if it
+// has changed since the last sync.
+func (c *Controller) updateClusterStatus(cvsc *v1alpha1.ClusterVaultSecretClaim, status v1alpha1.ClusterVaultSecretClaimStatus) error {
+	if reflect.DeepEqual(cvsc.Status, status) {
+		return nil
+	}
+
+	claim := cvsc.DeepCopy()
+	claim.Status = status
+
+	updated, err := c.clientset.DwellerV1alpha1().ClusterVaultSecretClaims().UpdateStatus(claim)
+	if err != nil {
+		return fmt.Errorf("update cluster vault secret claim status: %v", err)
+	}
+
+	cvsc.Status = status
+	cvsc.ResourceVersion = updated.ResourceVersion
+	return nil
+}
@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// hasConfigMapItems reports whether vault secret claim routes any of its data
+// items to the config map.
+func hasConfigMapItems(vsc *v1alpha1.VaultSecretClaim) bool {
+	for _, item := range vsc.DataItems() {
+		if item.ConfigMap {
+			return true
+		}
+	}
+	return false
+}
+
+// getConfigMap returns the config map of vault secret claim or nil if there is
+// no such config map or it is not owned by the claim. Conflict is reported if
+// the claim needs a config map but it is not owned by the claim.
+func (c *Controller) getConfigMap(vsc *v1alpha1.VaultSecretClaim) (*corev1.ConfigMap, error) {
+	c.logger.Debugf("Looking for ConfigMap \"%s/%s\"", vsc.Namespace, vsc.Name)
+	cm, err := c.configMapLister.ConfigMaps(vsc.Namespace).Get(vsc.Name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !metav1.IsControlledBy(cm, vsc) {
+		if hasConfigMapItems(vsc) {
+			return nil, c.reportConflict(vsc, "config map", cm)
+		}
+		// Not ours and we don't need it.
+		return nil, nil
+	}
+
+	return cm, nil
+}
+
+// extractConfigMapData moves values of data items routed to the config map out
+// of the secret. It returns nil if there are no such items.
+func extractConfigMapData(items []v1alpha1.DataItem, sec *corev1.Secret) map[string]string {
+	var data map[string]string
+	for _, item := range items {
+		if !item.ConfigMap {
+			continue
+		}
+		if data == nil {
+			data = make(map[string]string)
+		}
+
+		value, ok := sec.StringData[item.Key]
+		if !ok {
+			// Item is skipped.
+			continue
+		}
+
+		data[item.Key] = value
+		delete(sec.StringData, item.Key)
+	}
+
+	return data
+}
+
+// syncConfigMap creates, updates or deletes the config map of vault secret
+// claim. The config map gets the same labels, annotations and owner references
+// as the secret described by meta. Nil data means the claim needs no config
+// map, so the current one is deleted if it exists.
+func (c *Controller) syncConfigMap(vsc *v1alpha1.VaultSecretClaim, current *corev1.ConfigMap, meta metav1.ObjectMeta, data map[string]string) error {
+	if data == nil {
+		if current == nil {
+			return nil
+		}
+
+		err := c.client.CoreV1().ConfigMaps(current.Namespace).Delete(current.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete kubernetes config map: %v", err)
+		}
+		c.logger.Infof("ConfigMap \"%s/%s\" is not needed anymore and has been deleted", current.Namespace, current.Name)
+		return nil
+	}
+
+	if current == nil {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            vsc.Name,
+				Namespace:       vsc.Namespace,
+				Labels:          meta.Labels,
+				Annotations:     meta.Annotations,
+				OwnerReferences: meta.OwnerReferences,
+			},
+			Data: data,
+		}
+
+		_, err := c.client.CoreV1().ConfigMaps(cm.Namespace).Create(cm)
+		if err != nil {
+			return writeError("create kubernetes config map", err)
+		}
+		metrics.Add(metricConfigMapWrites, 1)
+		c.logger.Infof("ConfigMap \"%s/%s\" has been created", cm.Namespace, cm.Name)
+		return nil
+	}
+
+	if equalStringMaps(current.Labels, meta.Labels) && equalStringMaps(current.Annotations, meta.Annotations) && equalStringMaps(current.Data, data) {
+		metrics.Add(metricConfigMapWritesSkipped, 1)
+		c.logger.Debugf("ConfigMap \"%s/%s\" is up to date", current.Namespace, current.Name)
+		return nil
+	}
+
+	// Deep-copy otherwise we are mutating our cache.
+	cm := current.DeepCopy()
+	cm.Labels = meta.Labels
+	cm.Annotations = meta.Annotations
+	cm.Data = data
+
+	_, err := c.client.CoreV1().ConfigMaps(cm.Namespace).Update(cm)
+	if err != nil {
+		return writeError("update kubernetes config map", err)
+	}
+	metrics.Add(metricConfigMapWrites, 1)
+	c.logger.Infof("ConfigMap \"%s/%s\" has been updated", cm.Namespace, cm.Name)
+	return nil
+}
+
+// equalStringMaps reports whether the maps have the same entries. Nil and empty
+// maps are equal, since the API server doesn't tell them apart.
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// contentHashAnnotation holds the hash of the secret content produced by vault
+// secret claim, like "pod-template-hash" of replica sets.
+const contentHashAnnotation = "dweller.io/content-hash"
+
+// contentHash returns the hash of the secret content except the content hash
+// annotation itself. Empty labels and annotations are the same as missing
+// ones, since they are not stored by the API.
+func contentHash(sec *corev1.Secret) string {
+	sec = sec.DeepCopy()
+	delete(sec.Annotations, contentHashAnnotation)
+	if len(sec.Labels) == 0 {
+		sec.Labels = nil
+	}
+	if len(sec.Annotations) == 0 {
+		sec.Annotations = nil
+	}
+	return secret.Hash(sec)
+}
+
+// setContentHash sets the content hash annotation of the secret.
+func setContentHash(sec *corev1.Secret, hash string) {
+	annotations := make(map[string]string, len(sec.Annotations)+1)
+	for k, v := range sec.Annotations {
+		annotations[k] = v
+	}
+	annotations[contentHashAnnotation] = hash
+	sec.Annotations = annotations
+}
+
+// isUpToDate reports whether the secret has the content with the given hash.
+// The content is hashed again instead of trusting the annotation, so that
+// manual changes of the secret are not missed.
+func isUpToDate(sec *corev1.Secret, hash string) bool {
+	return sec.Annotations[contentHashAnnotation] == hash && contentHash(sec) == hash
+}
@@ -3,23 +3,30 @@ package controller
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
 	"github.com/fukt/dweller/pkg/client/clientset/versioned"
+	dwellerscheme "github.com/fukt/dweller/pkg/client/clientset/versioned/scheme"
 	"github.com/fukt/dweller/pkg/client/informers/externalversions"
 	dwellerlisters "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
 	"github.com/fukt/dweller/pkg/log"
@@ -27,10 +34,8 @@ import (
 )
 
 const (
-	maxRetries = 5
-
 	// builtinResync is resync period for built-it kubernetes objects, in our
-	// case secrets.
+	// case secrets and config maps.
 	builtinResync = time.Minute * 5
 
 	// customResync is resync period for custom resource definitions introduced
@@ -40,22 +45,98 @@ const (
 
 // Controller is a main dweller controller structure.
 type Controller struct {
-	builtinFactory informers.SharedInformerFactory
-	customFactory  externalversions.SharedInformerFactory
+	customFactory externalversions.SharedInformerFactory
+
+	// scope restricts watched objects. Objects of every watched namespace are
+	// watched by their own informers.
+	scope             Scope
+	namespaces        *namespaceSet
+	namespaceInformer cache.SharedIndexInformer
+
+	warmUpOnce sync.Once
+	warmUpErr  error
 
 	client    kubernetes.Interface
 	clientset versioned.Interface
 
 	logger log.Logger
 
+	// recorder records events on vault secret claims, events tracks the
+	// emitted ones to not to repeat them on resync.
+	recorder record.EventRecorder
+	events   *eventCache
+
 	queue workqueue.RateLimitingInterface
 
+	// workers is the number of workers processing the queue concurrently.
+	// A key is never processed by several workers at the same time.
+	workers int
+
+	// health reports whether the secret provider is available. Workers are
+	// paused while it's not.
+	health secret.Health
+
+	// retry defines how failed syncs are retried. Keys failed during the
+	// secret provider outage or too many times are parked.
+	retry  RetryPolicy
+	parked *parkedKeys
+
+	// rollouts queues workloads to be rolled out after secrets of vault
+	// secret claims they depend on have changed. Rollouts are rate limited
+	// by the rollout policy.
+	rollout         RolloutPolicy
+	rollouts        workqueue.RateLimitingInterface
+	pendingRollouts *pendingRollouts
+	rolloutLimiter  flowcontrol.RateLimiter
+
+	// apps lists and patches workloads.
+	apps *appsClient
+
+	// syncHandler syncs the vault secret claim with the given key. It's set
+	// to syncVaultSecretClaim and can be replaced in tests.
+	syncHandler func(key string) error
+
 	// secretLister can list/get secrets from the shared informer's store.
 	secretLister corelisters.SecretLister
 
+	// secretIndexer looks secrets up by controller owner and by claims
+	// contributing to shared secrets.
+	secretIndexer byIndexer
+
+	// configMapLister can list/get config maps from the shared informer's
+	// store.
+	configMapLister corelisters.ConfigMapLister
+
 	// vscLister can list/get vault secret claims from the shared informer's store.
 	vscLister dwellerlisters.VaultSecretClaimLister
 
+	// vscIndexer looks vault secret claims up by referenced template and by
+	// produced secret.
+	vscIndexer byIndexer
+
+	// claimTemplates enables vault secret claim templates. Templates are
+	// cluster-scoped, so they are watched regardless of the scope.
+	// templateLister can list/get them from the shared informer's store.
+	claimTemplates bool
+	templateLister dwellerlisters.VaultSecretClaimTemplateLister
+
+	// clusterClaims enables syncing of cluster vault secret claims. They are
+	// synced by their own worker from their own queue, since a sync writes
+	// secrets to many namespaces.
+	clusterClaims            bool
+	clusterQueue             workqueue.RateLimitingInterface
+	clusterClaimLister       dwellerlisters.ClusterVaultSecretClaimLister
+	clusterClaimIndexer      byIndexer
+	clusterNamespaceInformer cache.SharedIndexInformer
+	namespaceLister          corelisters.NamespaceLister
+
+	// pathPolicyInformer informs about vault path policies claims are
+	// authorized with, so that denied claims are retried once they change.
+	// pathAuthorizer authorizes namespaces secrets of cluster vault secret
+	// claims are written to.
+	pathPolicyInformer cache.SharedInformer
+	pathAuthorizer     secret.PathAuthorizer
+
 	// asm is a secret assembler that is used to create kubernetes secrets
 	// based on vault secret claim.
 	asm secret.Assembler
@@ -71,6 +152,24 @@ func WithLogger(lg log.Logger) Option {
 	}
 }
 
+// WithWorkers sets the number of workers syncing vault secret claims
+// concurrently. The default is one worker.
+func WithWorkers(workers int) Option {
+	return func(c *Controller) {
+		if workers > 0 {
+			c.workers = workers
+		}
+	}
+}
+
+// WithEventRecorder sets specified event recorder instead of the one recording
+// events to the kubernetes API.
+func WithEventRecorder(recorder record.EventRecorder) Option {
+	return func(c *Controller) {
+		c.recorder = recorder
+	}
+}
+
 // New returns newly created dweller controller or nil on error.
 func New(k8sConfig *rest.Config, client kubernetes.Interface, asm secret.Assembler, options ...Option) (*Controller, error) {
 	clientset, err := versioned.NewForConfig(k8sConfig)
@@ -83,37 +182,78 @@ func New(k8sConfig *rest.Config, client kubernetes.Interface, asm secret.Assembl
 		client:    client,
 		clientset: clientset,
 		asm:       asm,
+		events:    newEventCache(),
+		workers:   1,
+		health:    alwaysHealthy{},
+		retry:     DefaultRetryPolicy,
+		parked:    newParkedKeys(),
+		rollout:   DefaultRolloutPolicy,
 	}
+	ctrl.syncHandler = ctrl.syncVaultSecretClaim
 
 	for _, option := range options {
 		option(ctrl)
 	}
 
-	ctrl.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	if ctrl.recorder == nil {
+		// Vault secret claims must be known to the scheme to be referred
+		// by events.
+		dwellerscheme.AddToScheme(scheme.Scheme)
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+		ctrl.recorder = broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: controllerAgentName})
+	}
 
-	builtinFactory := informers.NewSharedInformerFactory(client, builtinResync)
-	customFactory := externalversions.NewSharedInformerFactory(clientset, customResync)
+	ctrl.queue = workqueue.NewRateLimitingQueue(ctrl.retry.rateLimiter())
+	ctrl.exportParkedKeys()
 
-	ctrl.builtinFactory = builtinFactory
-	ctrl.customFactory = customFactory
+	ctrl.rollouts = workqueue.NewRateLimitingQueue(ctrl.retry.rateLimiter())
+	ctrl.pendingRollouts = newPendingRollouts()
+	ctrl.rolloutLimiter = flowcontrol.NewTokenBucketRateLimiter(ctrl.rollout.QPS, ctrl.rollout.Burst)
+	ctrl.apps = newAppsClient(k8sConfig, client.Discovery())
 
-	ctrl.secretLister = builtinFactory.Core().V1().Secrets().Lister()
-	ctrl.vscLister = customFactory.Dweller().V1alpha1().VaultSecretClaims().Lister()
+	if len(ctrl.scope.Namespaces) > 0 && ctrl.scope.NamespaceSelector != nil {
+		return nil, fmt.Errorf("namespaces and namespace selector are mutually exclusive")
+	}
 
-	vscInformer := customFactory.Dweller().V1alpha1().VaultSecretClaims().Informer()
-	vscInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    ctrl.addVaultSecretClaim,
-		UpdateFunc: ctrl.updateVaultSecretClaim,
-		DeleteFunc: ctrl.deleteVaultSecretClaim,
-	})
+	ctrl.namespaces = &namespaceSet{
+		namespaces: make(map[string]*namespaceInformers),
+		empty:      ctrl.newNamespaceInformers(metav1.NamespaceAll),
+	}
+	switch {
+	case ctrl.scope.allNamespaces():
+		ctrl.watchNamespace(metav1.NamespaceAll)
+	case ctrl.scope.NamespaceSelector != nil:
+		ctrl.namespaceInformer = ctrl.newNamespaceInformer()
+	default:
+		for _, namespace := range ctrl.scope.Namespaces {
+			ctrl.watchNamespace(namespace)
+		}
+	}
 
-	// TODO: we should also watch for secrets to:
-	// 1) enforce vsc definitions;
-	// 2) watch if conflicting secrets were deleted and we can safely create new.
+	ctrl.secretLister = scopedSecretLister{namespaces: ctrl.namespaces}
+	ctrl.secretIndexer = scopedIndexer{
+		namespaces: ctrl.namespaces,
+		informer:   func(ni *namespaceInformers) cache.SharedIndexInformer { return ni.secrets },
+	}
+	ctrl.configMapLister = scopedConfigMapLister{namespaces: ctrl.namespaces}
+	ctrl.vscLister = scopedClaimLister{namespaces: ctrl.namespaces}
+	ctrl.vscIndexer = scopedIndexer{
+		namespaces: ctrl.namespaces,
+		informer:   func(ni *namespaceInformers) cache.SharedIndexInformer { return ni.claims },
+	}
+
+	customFactory := externalversions.NewSharedInformerFactory(clientset, customResync)
+	ctrl.customFactory = customFactory
 
-	// Hacky stuff.
-	utilruntime.ErrorHandlers[0] = func(err error) {
-		ctrl.logger.Errorf(err.Error())
+	if ctrl.claimTemplates {
+		ctrl.setUpClaimTemplates()
+	}
+	if ctrl.clusterClaims {
+		ctrl.setUpClusterClaims()
+	}
+	if ctrl.pathPolicyInformer != nil {
+		ctrl.setUpPathPolicies()
 	}
 
 	return ctrl, nil
@@ -125,38 +265,85 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	go func() {
 		<-stopCh
 		c.queue.ShutDown()
+		c.rollouts.ShutDown()
+		if c.clusterQueue != nil {
+			c.clusterQueue.ShutDown()
+		}
 	}()
 
 	c.logger.Infof("Starting dweller controller")
 	defer c.logger.Infof("Shutting down dweller controller")
 
-	c.builtinFactory.Start(stopCh)
-	c.customFactory.Start(stopCh)
-
-	if err := c.syncInformersCache(stopCh); err != nil {
-		utilruntime.HandleError(err)
+	if err := c.WarmUp(stopCh); err != nil {
+		c.logger.Errorf("%v", err)
 		return
 	}
 
 	c.logger.Infof("Dweller controller synced and ready")
 
-	wait.Until(c.runWorker, time.Second, stopCh)
+	c.runWorkers(c.workers, stopCh)
 }
 
-func (c *Controller) syncInformersCache(stopCh <-chan struct{}) error {
-	var syncedInformers map[reflect.Type]bool
-	var errs []error
+// runWorkers starts the workers and blocks until stopCh is closed. The queue
+// guarantees that a key is processed by a single worker at a time: a key added
+// while it's being processed is handed out only after it's done.
+func (c *Controller) runWorkers(workers int, stopCh <-chan struct{}) {
+	c.logger.Infof("Starting %d workers", workers)
+	for i := 0; i < workers; i++ {
+		go wait.Until(c.runWorker, time.Second, stopCh)
+	}
+	go c.watchHealth(stopCh)
+	go wait.Until(c.runRolloutWorker, time.Second, stopCh)
+	if c.clusterQueue != nil {
+		go wait.Until(c.runClusterWorker, time.Second, stopCh)
+	}
 
-	// Sync all built-in informers that we are using in the controller.
-	syncedInformers = c.builtinFactory.WaitForCacheSync(stopCh)
-	for inf, synced := range syncedInformers {
-		if !synced {
-			errs = append(errs, fmt.Errorf("Couldn't sync cache for %v", inf))
-		} else {
-			c.logger.Debugf("Synced cache for %s", inf)
+	<-stopCh
+}
+
+// WarmUp starts informers and waits for their caches to sync. Standby replicas
+// warm up in advance to start syncing claims as soon as they become leaders.
+// Run warms the controller up unless it's already done.
+func (c *Controller) WarmUp(stopCh <-chan struct{}) error {
+	c.warmUpOnce.Do(func() {
+		c.warmUpErr = c.warmUp(stopCh)
+	})
+	return c.warmUpErr
+}
+
+func (c *Controller) warmUp(stopCh <-chan struct{}) error {
+	if c.namespaceInformer != nil {
+		go c.namespaceInformer.Run(stopCh)
+		if !cache.WaitForCacheSync(stopCh, c.namespaceInformer.HasSynced) {
+			return fmt.Errorf("couldn't sync cache for namespaces")
+		}
+		// Event handlers might not have been notified about all the
+		// namespaces yet.
+		for _, obj := range c.namespaceInformer.GetStore().List() {
+			c.watchNamespace(obj.(*corev1.Namespace).Name)
 		}
 	}
 
+	if !cache.WaitForCacheSync(stopCh, c.startNamespaces()...) {
+		return fmt.Errorf("couldn't sync cache for watched namespaces")
+	}
+	c.logger.Debugf("Synced cache for watched namespaces")
+
+	if c.clusterNamespaceInformer != nil {
+		go c.clusterNamespaceInformer.Run(stopCh)
+		if !cache.WaitForCacheSync(stopCh, c.clusterNamespaceInformer.HasSynced) {
+			return fmt.Errorf("couldn't sync cache for namespaces of cluster claims")
+		}
+	}
+
+	c.customFactory.Start(stopCh)
+	return c.syncInformersCache(stopCh)
+}
+
+func (c *Controller) syncInformersCache(stopCh <-chan struct{}) error {
+	var syncedInformers map[reflect.Type]bool
+	var errs []error
+
 	// Sync custom informers.
 	syncedInformers = c.customFactory.WaitForCacheSync(stopCh)
 	for inf, synced := range syncedInformers {
@@ -179,6 +366,15 @@ func (c *Controller) addVaultSecretClaim(obj interface{}) {
 func (c *Controller) updateVaultSecretClaim(old, new interface{}) {
 	oldVsc := old.(*v1alpha1.VaultSecretClaim)
 	newVsc := new.(*v1alpha1.VaultSecretClaim)
+	if isStatusUpdate(oldVsc, newVsc) {
+		// Status is written by the controller itself on every sync, there is
+		// nothing to do about it.
+		return
+	}
+	if oldVsc.ResourceVersion == newVsc.ResourceVersion && hasRefreshInterval(newVsc) {
+		// Periodic resync, the claim is refreshed on its own schedule.
+		return
+	}
 	c.logger.Infof("Updating VaultSecretClaim \"%s/%s\"", oldVsc.Namespace, oldVsc.Name)
 	c.enqueue(newVsc)
 }
@@ -193,21 +389,31 @@ func (c *Controller) deleteVaultSecretClaim(obj interface{}) {
 
 	tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
 	if !ok {
-		utilruntime.HandleError(fmt.Errorf("Couldn't get object from tombstone %#v", obj))
+		c.logger.Errorf("Couldn't get object from tombstone %#v", obj)
 		return
 	}
 	vsc, ok = tombstone.Obj.(*v1alpha1.VaultSecretClaim)
 	if !ok {
-		utilruntime.HandleError(fmt.Errorf("Tombstone contained object that is not a VaultSecretClaim %#v", obj))
+		c.logger.Errorf("Tombstone contained object that is not a VaultSecretClaim %#v", obj)
 		return
 	}
+	// The claim was deleted while the watch was down. It's synced anyway, so
+	// that its keys are released from shared secrets.
+	c.logger.Infof("Deleting VaultSecretClaim \"%s/%s\"", vsc.Namespace, vsc.Name)
+	c.queue.Add(tombstone.Key)
+}
+
+// hasRefreshInterval reports whether vault secret claim is refreshed on its own
+// schedule.
+func hasRefreshInterval(vsc *v1alpha1.VaultSecretClaim) bool {
+	return vsc.Spec.RefreshInterval != nil && vsc.Spec.RefreshInterval.Duration > 0
 }
 
 // enqueue adds vault secret claim to the queue.
 func (c *Controller) enqueue(vsc *v1alpha1.VaultSecretClaim) {
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(vsc)
 	if err != nil {
-		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %#v: %v", vsc, err))
+		c.logger.Errorf("Couldn't get key for object %#v: %v", vsc, err)
 		return
 	}
 
@@ -215,7 +421,7 @@ func (c *Controller) enqueue(vsc *v1alpha1.VaultSecretClaim) {
 }
 
 func (c *Controller) runWorker() {
-	for c.processNextItem() {
+	for c.waitHealthy() && c.processNextItem() {
 		// continue looping
 	}
 }
@@ -227,28 +433,108 @@ func (c *Controller) processNextItem() bool {
 	}
 	defer c.queue.Done(key)
 
-	err := c.syncVaultSecretClaim(key.(string))
+	err := c.syncHandler(key.(string))
 	c.handleProcessingError(err, key)
+	c.scheduleRefresh(key.(string))
 
 	return true
 }
 
+// scheduleRefresh schedules the next sync of vault secret claim with the given
+// key according to its refresh interval, if any. Claims without the interval
+// are refreshed on resync.
+func (c *Controller) scheduleRefresh(key string) {
+	vsc := c.claimByKey(key)
+	if vsc == nil || !hasRefreshInterval(vsc) {
+		return
+	}
+
+	// The queue keeps the earliest time if the key is already scheduled, so
+	// retries of failed syncs are not delayed.
+	c.queue.AddAfter(key, vsc.Spec.RefreshInterval.Duration)
+}
+
 func (c *Controller) handleProcessingError(err error, key interface{}) {
 	if err == nil {
 		// No error, reset the ratelimit counters
 		c.queue.Forget(key)
+		if c.parked.remove(key.(string)) {
+			c.logger.Infof("VaultSecretClaim %q is synced and not parked anymore", key)
+		}
 		return
 	}
 
-	if c.queue.NumRequeues(key) < maxRetries {
-		utilruntime.HandleError(fmt.Errorf("Error processing %s (will retry): %v", key, err))
+	if err == secret.ErrUnavailable || !c.health.Healthy() {
+		// Don't waste retries while the secret provider is down, the key is
+		// requeued once it recovers.
+		c.logger.Errorf("Error processing %s (parked until secret provider recovers): %v", key, err)
+		c.recordSyncError(key.(string), err, reasonParked)
+		c.queue.Forget(key)
+		c.parked.add(key.(string), true)
+		// The provider may have recovered since the error, after the parked
+		// keys were requeued, so the key is also retried on the parked
+		// interval not to be stranded.
+		c.queue.AddAfter(key, c.retry.ParkedInterval)
+		return
+	}
+
+	switch secret.ClassOf(err) {
+	case secret.ClassConflict:
+		// The conflict is already reported, the claim is synced again once
+		// the conflicting object changes.
+		c.logger.Warnf("Error processing %s (waiting for the conflict to be resolved): %v", key, err)
+		c.queue.Forget(key)
+		c.parked.remove(key.(string))
+		return
+	case secret.ClassInvalidSpec:
+		// Retrying won't help, the claim is synced again once its spec
+		// changes.
+		c.logger.Warnf("Error processing %s (waiting for the spec to be fixed): %v", key, err)
+		c.recordSyncError(key.(string), err, reasonInvalidSpec)
+		if vsc := c.claimByKey(key.(string)); vsc != nil {
+			c.event(vsc, corev1.EventTypeWarning, eventInvalidSpec, "", "%v", err)
+		}
+		c.queue.Forget(key)
+		c.parked.remove(key.(string))
+		return
+	}
+
+	if c.parked.has(key.(string)) {
+		// Parked keys are not retried with backoff, but re-attempted on
+		// a slower cadence or when related objects change.
+		c.logger.Errorf("Error processing %s (parked, will retry in %v): %v", key, c.retry.ParkedInterval, err)
+		c.recordSyncError(key.(string), err, reasonParked)
+		c.queue.AddAfter(key, c.retry.ParkedInterval)
+		return
+	}
+
+	if class := secret.ClassOf(err); class == secret.ClassPermission || class == secret.ClassNotFound {
+		// Access is rarely granted and secrets are rarely created within
+		// the backoff, so the key is parked right away.
+		c.logger.Errorf("Error processing %s (parked, will retry in %v): %v", key, c.retry.ParkedInterval, err)
+		c.recordSyncError(key.(string), err, reasonParked)
+		c.queue.Forget(key)
+		c.parked.add(key.(string), false)
+		c.queue.AddAfter(key, c.retry.ParkedInterval)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < c.retry.MaxRetries {
+		c.logger.Errorf("Error processing %s (will retry): %v", key, err)
+		c.recordSyncError(key.(string), err, reasonSyncFailed)
 		c.queue.AddRateLimited(key)
 		return
 	}
 
 	// too many retries
-	utilruntime.HandleError(fmt.Errorf("Error processing %s (giving up): %v", key, err))
+	c.logger.Errorf("Error processing %s (parked, will retry in %v): %v", key, c.retry.ParkedInterval, err)
+	c.recordSyncError(key.(string), err, reasonParked)
+	if vsc := c.claimByKey(key.(string)); vsc != nil {
+		c.event(vsc, corev1.EventTypeWarning, eventRetriesExhausted, "", "Parked after %d retries, will retry in %v: %v", c.retry.MaxRetries, c.retry.ParkedInterval, err)
+	}
 	c.queue.Forget(key)
+	c.parked.add(key.(string), false)
+	c.queue.AddAfter(key, c.retry.ParkedInterval)
 }
 
 // syncVaultSecretClaim will sync the vault secret claim with the given key.
@@ -269,10 +555,25 @@ func (c *Controller) syncVaultSecretClaim(key string) error {
 
 	vaultSecretClaim, err := c.vscLister.VaultSecretClaims(namespace).Get(name)
 	if apierrors.IsNotFound(err) {
+		// A claim that stopped matching the claim selector is gone from the
+		// cache as well, but it must be left alone rather than released.
+		_, err := c.clientset.DwellerV1alpha1().VaultSecretClaims(namespace).Get(name, metav1.GetOptions{})
+		if err == nil {
+			c.logger.Infof("VaultSecretClaim %v is out of the scope, leaving it alone", key)
+			c.events.forget(key)
+			return nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("get vault secret claim: %v", err)
+		}
+
 		// VaultSecretClaim's child secret will be automatically garbage
-		// collected, so no need to delete it manually.
+		// collected, so no need to delete it manually. Shared secrets are
+		// collected only when all the contributing claims are deleted, so the
+		// keys of this one are removed explicitly.
 		c.logger.Infof("VaultSecretClaim %v has been deleted", key)
-		return nil
+		c.events.forget(key)
+		return c.releaseSharedSecrets(namespace, name, nil)
 	}
 	if err != nil {
 		return err
@@ -281,66 +582,332 @@ func (c *Controller) syncVaultSecretClaim(key string) error {
 	// Deep-copy otherwise we are mutating our cache.
 	vsc := vaultSecretClaim.DeepCopy()
 
-	c.logger.Debugf("Looking for Secret \"%s/%s\"", vsc.Namespace, vsc.Name)
-	relatedSecret, err := c.secretLister.Secrets(vsc.Namespace).Get(vsc.Name)
-	if apierrors.IsNotFound(err) {
-		c.logger.Debugf("Secret \"%s/%s\" was not found - VaultSecretClaim will create one", vsc.Namespace, vsc.Name)
-		if err := c.createSecret(vsc); err != nil {
+	// Claims are validated on admission if the validating webhook is
+	// registered, but might have been created before.
+	if errs := validation.ValidateVaultSecretClaim(vsc); len(errs) > 0 {
+		return secret.NewError(secret.ClassInvalidSpec, errs.ToAggregate())
+	}
+
+	if err := c.resolveTemplate(vsc); err != nil {
+		return err
+	}
+
+	templates := vsc.SecretTemplates()
+	if err := checkSecretNames(templates); err != nil {
+		return err
+	}
+
+	relatedSecrets, err := c.ownedSecrets(vsc)
+	if err != nil {
+		return err
+	}
+
+	if !vsc.Spec.Immutable {
+		// Check all the secrets beforehand to not to write any of them in case
+		// of conflict. Names of immutable secrets are not known in advance.
+		var unowned []*corev1.Secret
+		for _, tmpl := range templates {
+			if tmpl.Shared {
+				// Shared secrets are not owned by design.
+				continue
+			}
+
+			sec, err := c.unownedSecret(vsc, tmpl.Name)
+			if err != nil {
+				return err
+			}
+			if sec == nil {
+				continue
+			}
+			if !canAdopt(vsc, sec) {
+				return c.reportConflict(vsc, "secret", sec)
+			}
+			unowned = append(unowned, sec)
+		}
+
+		if len(unowned) > 0 {
+			if err := c.adoptSecrets(vsc, unowned, relatedSecrets); err != nil {
+				return err
+			}
+		}
+	}
+
+	relatedConfigMap, err := c.getConfigMap(vsc)
+	if err != nil {
+		return err
+	}
+
+	var (
+		skipped       []v1alpha1.SkippedItem
+		statuses      []v1alpha1.SecretStatus
+		configMapMeta metav1.ObjectMeta
+		configMapData map[string]string
+		sharedSecrets = make(map[string]bool)
+	)
+
+	for i := range templates {
+		tmpl := &templates[i]
+
+		sec, tmplSkipped, err := c.asm.Assemble(vsc, tmpl)
+		if err != nil {
 			return err
 		}
-		c.logger.Infof("Secret for VaultSecretClaim %v has been created", key)
+
+		relatedSecret := relatedSecrets[currentSecretName(vsc, tmpl.Name)]
+		if tmpl.Shared {
+			relatedSecret, err = c.secretLister.Secrets(vsc.Namespace).Get(tmpl.Name)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		if vsc.Spec.FailurePolicy == v1alpha1.KeepLastKnown {
+			keepLastKnown(tmplSkipped, lastKnownValues(relatedSecret, relatedConfigMap), sec.StringData)
+		}
+		skipped = append(skipped, tmplSkipped...)
+
+		if data := extractConfigMapData(tmpl.Data, &sec); data != nil {
+			if configMapData == nil {
+				configMapMeta = sec.ObjectMeta
+				configMapData = make(map[string]string)
+			}
+			for k, v := range data {
+				configMapData[k] = v
+			}
+		}
+
+		// The hash is taken before the sync, which might add labels of its
+		// own, so that it reflects the content produced by the claim.
+		hash := contentHash(&sec)
+
+		switch {
+		case tmpl.Shared:
+			sharedSecrets[tmpl.Name] = true
+			if err := c.syncSharedSecret(vsc, &sec); err != nil {
+				return err
+			}
+		case vsc.Spec.Immutable:
+			if err := c.syncImmutableSecret(vsc, &sec); err != nil {
+				return err
+			}
+		default:
+			if err := c.syncSecret(vsc, relatedSecret, &sec); err != nil {
+				return err
+			}
+		}
+
+		statuses = append(statuses, v1alpha1.SecretStatus{Target: tmpl.Name, Name: sec.Name, Hash: hash})
+	}
+
+	if err := c.syncConfigMap(vsc, relatedConfigMap, configMapMeta, configMapData); err != nil {
+		return err
+	}
+
+	status := vsc.Status.DeepCopy()
+	status.ObservedGeneration = vsc.Generation
+	status.ItemErrors = nil
+	status.SkippedItems = skipped
+	status.Secrets = statuses
+	status.SecretName = ""
+	status.ContentHash = ""
+	if len(statuses) > 0 {
+		status.SecretName = statuses[0].Name
+		status.ContentHash = statuses[0].Hash
+	}
+	setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionTrue, reasonSynced, "")
+	setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionTrue, reasonSecretsReady, "")
+	status.LastSyncTime = lastSyncTime(status.LastSyncTime, !reflect.DeepEqual(vsc.Status, *status))
+	if err := c.updateStatus(vsc, *status); err != nil {
+		return err
+	}
+	c.events.forgetWarnings(key)
+
+	if err := c.releaseSharedSecrets(vsc.Namespace, vsc.Name, sharedSecrets); err != nil {
+		return err
+	}
+
+	return c.deleteStaleSecrets(vsc, relatedSecrets, statuses)
+}
+
+// checkSecretNames checks that secret templates have distinct names.
+func checkSecretNames(templates []v1alpha1.SecretTemplate) error {
+	names := make(map[string]bool, len(templates))
+	for _, tmpl := range templates {
+		if names[tmpl.Name] {
+			return secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("secret %q is declared more than once", tmpl.Name))
+		}
+		names[tmpl.Name] = true
+	}
+	return nil
+}
+
+// ownerIndex is the name of secret informer index by controller owner UID.
+const ownerIndex = "owner"
+
+// indexByOwner indexes objects by UID of their controller owner.
+func indexByOwner(obj interface{}) ([]string, error) {
+	meta, err := apimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := metav1.GetControllerOf(meta)
+	if ref == nil {
+		return nil, nil
+	}
+	return []string{string(ref.UID)}, nil
+}
+
+// ownedSecrets returns all the secrets controlled by vault secret claim by
+// their names.
+func (c *Controller) ownedSecrets(vsc *v1alpha1.VaultSecretClaim) (map[string]*corev1.Secret, error) {
+	objs, err := c.secretIndexer.ByIndex(ownerIndex, string(vsc.UID))
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]*corev1.Secret)
+	for _, obj := range objs {
+		sec := obj.(*corev1.Secret)
+		if sec.Namespace == vsc.Namespace {
+			owned[sec.Name] = sec
+		}
+	}
+	return owned, nil
+}
+
+// claimByKey returns vault secret claim with the given key from the cache or
+// nil if there is no such claim.
+func (c *Controller) claimByKey(key string) *v1alpha1.VaultSecretClaim {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
 		return nil
 	}
+	vsc, err := c.vscLister.VaultSecretClaims(namespace).Get(name)
+	if err != nil {
+		return nil
+	}
+	return vsc
+}
 
+// unownedSecret returns the secret with the given name if it exists and is not
+// owned by vault secret claim.
+func (c *Controller) unownedSecret(vsc *v1alpha1.VaultSecretClaim, name string) (*corev1.Secret, error) {
+	c.logger.Debugf("Looking for Secret \"%s/%s\"", vsc.Namespace, name)
+	sec, err := c.secretLister.Secrets(vsc.Namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Deep-copy otherwise we are mutating our cache.
-	sec := relatedSecret.DeepCopy()
+	if metav1.IsControlledBy(sec, vsc) {
+		return nil, nil
+	}
+	return sec, nil
+}
+
+// currentSecretName returns the name of the secret that was produced from the
+// secret template by the last sync. Shared secrets are not in the list.
+func currentSecretName(vsc *v1alpha1.VaultSecretClaim, target string) string {
+	if !vsc.Spec.Immutable {
+		return target
+	}
+
+	for _, status := range vsc.Status.Secrets {
+		if status.Target == target {
+			return status.Name
+		}
+	}
+	return ""
+}
+
+// syncSecret creates the secret or updates the related one, if any. The update
+// is skipped if the related secret content is the same.
+func (c *Controller) syncSecret(vsc *v1alpha1.VaultSecretClaim, relatedSecret, sec *corev1.Secret) error {
+	hash := contentHash(sec)
+	setContentHash(sec, hash)
+
+	if relatedSecret == nil {
+		c.logger.Debugf("Secret \"%s/%s\" was not found - VaultSecretClaim will create one", sec.Namespace, sec.Name)
+		if err := c.createSecret(sec); err != nil {
+			return err
+		}
+		c.logger.Infof("Secret \"%s/%s\" has been created", sec.Namespace, sec.Name)
+		c.recorder.Eventf(vsc, corev1.EventTypeNormal, eventSecretCreated, "Secret %q has been created", sec.Name)
+		return nil
+	}
 
-	if !metav1.IsControlledBy(sec, vsc) {
-		err := fmt.Errorf("Conflict: found secret \"%s/%s\" that is not owned by vault secret claim. This must be resolved manually.", sec.Namespace, sec.Name)
-		utilruntime.HandleError(err)
-		return nil // Don't need to retry.
+	if isUpToDate(relatedSecret, hash) {
+		metrics.Add(metricSecretWritesSkipped, 1)
+		c.logger.Debugf("Secret \"%s/%s\" is up to date", sec.Namespace, sec.Name)
+		return nil
 	}
 
 	// TODO: It might be worthwhile to revisit creation/updating
 	// logic to handle all the fields properly.
 
 	// Found a secret, and it is controlled by vault secret claim.
-	// Just sync it.
-	if err := c.updateSecret(vsc, sec); err != nil {
+	// Just sync it. Deep-copy otherwise we are mutating our cache.
+	if err := c.updateSecret(relatedSecret.DeepCopy(), sec); err != nil {
 		return err
 	}
-	c.logger.Infof("Secret for VaultSecretClaim %v has been updated", key)
+	c.logger.Infof("Secret \"%s/%s\" has been updated", sec.Namespace, sec.Name)
+	c.event(vsc, corev1.EventTypeNormal, eventSecretUpdated, sec.Name, "Secret %q has been updated to content %s", sec.Name, hash)
+	c.rolloutDependents(vsc, sec.Name, hash)
 	return nil
 }
 
-func (c *Controller) createSecret(vsc *v1alpha1.VaultSecretClaim) error {
-	sec, err := c.asm.Assemble(vsc)
-	if err != nil {
-		return err
+// deleteStaleSecrets deletes secrets controlled by vault secret claim that are
+// not produced by it anymore. Old immutable secrets within the revision
+// history limit are retained.
+func (c *Controller) deleteStaleSecrets(vsc *v1alpha1.VaultSecretClaim, owned map[string]*corev1.Secret, current []v1alpha1.SecretStatus) error {
+	retained := make(map[string]bool)
+	for _, status := range current {
+		retained[status.Name] = true
+	}
+	if vsc.Spec.Immutable {
+		for _, name := range retainedImmutableSecrets(vsc, owned, current) {
+			retained[name] = true
+		}
 	}
 
-	_, err = c.client.CoreV1().Secrets(vsc.Namespace).Create(&sec)
-	if err != nil {
-		return fmt.Errorf("create kubernetes secret: %v", err)
+	for name, sec := range owned {
+		if retained[name] {
+			continue
+		}
+
+		err := c.client.CoreV1().Secrets(sec.Namespace).Delete(sec.Name, &metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete kubernetes secret: %v", err)
+		}
+		c.logger.Infof("Stale Secret \"%s/%s\" has been deleted", sec.Namespace, sec.Name)
 	}
 
 	return nil
 }
 
-func (c *Controller) updateSecret(vsc *v1alpha1.VaultSecretClaim, secret *corev1.Secret) error {
-	// TODO: compute and compare hashes to not to do worthless updates if vault
-	// secret claim is not actually changed (for example in case of resync).
-	// Implement something like "pod-template-hash" in ReplicaSet.
+// reportConflict reports that the object claimed by vault secret claim already
+// exists and is not owned by the claim. It returns the conflict error for the
+// sync to stop with.
+func (c *Controller) reportConflict(vsc *v1alpha1.VaultSecretClaim, kind string, obj metav1.Object) error {
+	err := fmt.Errorf("Conflict: found %s \"%s/%s\" that is not owned by vault secret claim. This must be resolved manually.", kind, obj.GetNamespace(), obj.GetName())
+	c.recordConflict(vsc, err)
+	return secret.NewError(secret.ClassConflict, err)
+}
 
-	newSecret, err := c.asm.Assemble(vsc)
+func (c *Controller) createSecret(sec *corev1.Secret) error {
+	_, err := c.client.CoreV1().Secrets(sec.Namespace).Create(sec)
 	if err != nil {
-		return err
+		return writeError("create kubernetes secret", err)
 	}
+	metrics.Add(metricSecretWrites, 1)
+
+	return nil
+}
 
+func (c *Controller) updateSecret(secret, newSecret *corev1.Secret) error {
 	// In meta, we need to update only labels and annotations.
 	secret.ObjectMeta.Labels = newSecret.Labels
 	secret.ObjectMeta.Annotations = newSecret.Annotations
@@ -349,10 +916,57 @@ func (c *Controller) updateSecret(vsc *v1alpha1.VaultSecretClaim, secret *corev1
 	secret.Data = nil
 	secret.StringData = newSecret.StringData
 
-	_, err = c.client.CoreV1().Secrets(secret.Namespace).Update(secret)
+	_, err := c.client.CoreV1().Secrets(secret.Namespace).Update(secret)
 	if err != nil {
-		return fmt.Errorf("update kubernetes secret: %v", err)
+		return writeError("update kubernetes secret", err)
 	}
+	metrics.Add(metricSecretWrites, 1)
 
 	return nil
 }
+
+// writeError returns the error of writing an object produced by vault secret
+// claim. The object is rejected as invalid only if the claim spec is, e.g. if
+// it sets a malformed label or key.
+func writeError(action string, err error) error {
+	wrapped := fmt.Errorf("%s: %v", action, err)
+	if apierrors.IsInvalid(err) {
+		return secret.NewError(secret.ClassInvalidSpec, wrapped)
+	}
+	return wrapped
+}
+
+// lastKnownValues returns values currently stored in the secret and the config
+// map, any of them might be nil.
+func lastKnownValues(sec *corev1.Secret, cm *corev1.ConfigMap) map[string]string {
+	values := make(map[string]string)
+	if sec != nil {
+		for k, v := range sec.Data {
+			values[k] = string(v)
+		}
+	}
+	if cm != nil {
+		for k, v := range cm.Data {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// keepLastKnown sets last known values of skipped data items to the data.
+func keepLastKnown(skipped []v1alpha1.SkippedItem, lastKnown, data map[string]string) {
+	for i, item := range skipped {
+		if _, ok := data[item.Key]; ok {
+			// Value is already provided, e.g. it's a default one.
+			continue
+		}
+
+		value, ok := lastKnown[item.Key]
+		if !ok {
+			continue
+		}
+
+		data[item.Key] = value
+		skipped[i].Reason += ", last known value is kept"
+	}
+}
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	dwellerlisters "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/log"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+func newTestController(syncHandler func(key string) error) *Controller {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	return &Controller{
+		logger:      &log.Dummy{},
+		queue:       workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		vscLister:   dwellerlisters.NewVaultSecretClaimLister(indexer),
+		events:      newEventCache(),
+		health:      alwaysHealthy{},
+		retry:       DefaultRetryPolicy,
+		parked:      newParkedKeys(),
+		syncHandler: syncHandler,
+
+		rollouts:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		pendingRollouts: newPendingRollouts(),
+		rolloutLimiter:  flowcontrol.NewFakeAlwaysRateLimiter(),
+	}
+}
+
+func TestWorkersDoNotProcessSameKeyConcurrently(t *testing.T) {
+	const (
+		workers = 4
+		keys    = 3
+		syncs   = 100
+	)
+
+	var (
+		mu        sync.Mutex
+		inFlight  = make(map[string]bool)
+		processed int
+		done      = make(chan struct{})
+	)
+
+	var c *Controller
+	c = newTestController(func(key string) error {
+		mu.Lock()
+		if inFlight[key] {
+			t.Errorf("key %q is processed by several workers at the same time", key)
+		}
+		inFlight[key] = true
+		mu.Unlock()
+
+		// Add the key again while it's being processed to give other workers
+		// a chance to pick it up.
+		c.queue.Add(key)
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		inFlight[key] = false
+		processed++
+		if processed == syncs {
+			close(done)
+		}
+		mu.Unlock()
+		return nil
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	defer c.queue.ShutDown()
+	defer c.rollouts.ShutDown()
+
+	go c.runWorkers(workers, stopCh)
+	for i := 0; i < keys; i++ {
+		c.queue.Add(fmt.Sprintf("default/claim-%d", i))
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for %d syncs", syncs)
+	}
+}
+
+func TestSlowKeyDoesNotBlockOtherKeys(t *testing.T) {
+	release := make(chan struct{})
+	fastDone := make(chan struct{})
+
+	c := newTestController(func(key string) error {
+		switch key {
+		case "default/slow":
+			<-release
+		case "default/fast":
+			close(fastDone)
+		}
+		return nil
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	defer c.queue.ShutDown()
+	defer c.rollouts.ShutDown()
+	defer close(release)
+
+	go c.runWorkers(2, stopCh)
+	c.queue.Add("default/slow")
+	c.queue.Add("default/fast")
+
+	select {
+	case <-fastDone:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the fast key while the slow one is being synced")
+	}
+}
+
+func TestHandleProcessingErrorByClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		retried bool
+		parked  bool
+	}{
+		{"transient", fmt.Errorf("connection refused"), true, false},
+		{"permission", &secret.PermissionError{Path: "secret/postgres", Err: fmt.Errorf("Code: 403.")}, false, true},
+		{"not found", secret.ItemErrors{{Key: "PASSWORD", Err: &secret.NotFoundError{Path: "secret/postgres", Field: "password"}}}, false, true},
+		{"invalid spec", secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("secret %q is declared more than once", "postgres")), false, false},
+		{"conflict", secret.NewError(secret.ClassConflict, fmt.Errorf("Conflict")), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const key = "default/postgres"
+			c := newTestController(nil)
+			defer c.queue.ShutDown()
+
+			c.handleProcessingError(tt.err, key)
+
+			if retried := c.queue.NumRequeues(key) > 0; retried != tt.retried {
+				t.Errorf("retried = %v, want %v", retried, tt.retried)
+			}
+			if parked := c.parked.has(key); parked != tt.parked {
+				t.Errorf("parked = %v, want %v", parked, tt.parked)
+			}
+		})
+	}
+}
+
+// newAppsServer serves deployments of the default namespace at apps/v1 and
+// nothing else.
+func newAppsServer(t *testing.T, deployments ...metav1.ObjectMeta) (*httptest.Server, *appsClient) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var items []map[string]interface{}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/default/deployments":
+			for _, meta := range deployments {
+				items = append(items, map[string]interface{}{"metadata": meta})
+			}
+		case "/apis/apps/v1/namespaces/default/statefulsets", "/apis/apps/v1/namespaces/default/daemonsets":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+
+	client, err := newAppsRESTClient(&rest.Config{Host: server.URL}, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, &appsClient{version: "v1", client: client}
+}
+
+func TestRolloutDependents(t *testing.T) {
+	deployment := func(name, claims string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Annotations: map[string]string{rolloutAnnotation: claims},
+		}
+	}
+
+	c := newTestController(nil)
+	defer c.rollouts.ShutDown()
+	server, apps := newAppsServer(t,
+		deployment("api", "postgres, redis"),
+		deployment("worker", "redis"),
+		metav1.ObjectMeta{Namespace: "default", Name: "web"},
+	)
+	defer server.Close()
+	c.apps = apps
+
+	vsc := &v1alpha1.VaultSecretClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "postgres"}}
+	c.rolloutDependents(vsc, "postgres", "abc")
+	c.rolloutDependents(vsc, "postgres-replication", "def")
+
+	// Dependents of the claim are listed by the rollout worker.
+	c.processNextRollout()
+
+	if n := c.rollouts.Len(); n != 1 {
+		t.Fatalf("%d workloads are queued, want 1", n)
+	}
+	got := c.pendingRollouts.take(workload{kind: kindDeployment, namespace: "default", name: "api"})
+	want := map[string]string{"postgres": "abc", "postgres-replication": "def"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pending checksums = %v, want %v", got, want)
+	}
+}
+
+func TestRolloutDependentsRetriesNotFoundList(t *testing.T) {
+	c := newTestController(nil)
+	defer c.rollouts.ShutDown()
+	server, apps := newAppsServer(t)
+	defer server.Close()
+
+	// The cluster doesn't serve the version anymore.
+	client, err := newAppsRESTClient(&rest.Config{Host: server.URL}, "v1beta2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apps.version, apps.client = "v1beta2", client
+	c.apps = apps
+
+	vsc := &v1alpha1.VaultSecretClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "postgres"}}
+	c.rolloutDependents(vsc, "postgres", "abc")
+	c.processNextRollout()
+
+	claim := workload{kind: kindClaimDependents, namespace: "default", name: "postgres"}
+	if n := c.rollouts.NumRequeues(claim); n != 1 {
+		t.Errorf("listing of dependents is retried %d times, want once", n)
+	}
+	if got := c.pendingRollouts.take(claim); got["postgres"] != "abc" {
+		t.Errorf("pending checksums = %v, want them to be kept for the retry", got)
+	}
+}
+
+// denyingAuthorizer allows the listed namespaces only.
+type denyingAuthorizer map[string]bool
+
+func (a denyingAuthorizer) Authorize(namespace, path string, engine v1alpha1.SecretEngine) error {
+	if a[namespace] {
+		return nil
+	}
+	return &secret.PolicyDeniedError{Namespace: namespace, Path: path, Engine: engine}
+}
+
+func TestAuthorizeClusterNamespace(t *testing.T) {
+	c := newTestController(nil)
+	defer c.queue.ShutDown()
+	defer c.rollouts.ShutDown()
+	c.pathAuthorizer = denyingAuthorizer{"team-a": true}
+
+	cvsc := &v1alpha1.ClusterVaultSecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+		Spec: v1alpha1.ClusterVaultSecretClaimSpec{
+			Secret: v1alpha1.SecretTemplate{
+				Data: []v1alpha1.DataItem{{Key: "TOKEN", VaultPath: "secret/registry", VaultField: "token"}},
+			},
+		},
+	}
+
+	if err := c.authorizeClusterNamespace(cvsc, "team-a"); err != nil {
+		t.Errorf("allowed namespace is denied: %v", err)
+	}
+	err := c.authorizeClusterNamespace(cvsc, "team-b")
+	if err == nil {
+		t.Fatal("denied namespace is allowed")
+	}
+	if _, denied := err.Err.(*secret.PolicyDeniedError); !denied || err.Key != "TOKEN" {
+		t.Errorf("got error %v, want policy denial of TOKEN", err)
+	}
+}
+
+func TestEnqueueConflictingClaims(t *testing.T) {
+	conflict := v1alpha1.VaultSecretClaimStatus{Conditions: []v1alpha1.VaultSecretClaimCondition{
+		{Type: v1alpha1.ClaimReady, Status: corev1.ConditionFalse, Reason: reasonConflict},
+	}}
+	claim := func(name string, spec v1alpha1.VaultSecretClaimSpec, status v1alpha1.VaultSecretClaimStatus) *v1alpha1.VaultSecretClaim {
+		return &v1alpha1.VaultSecretClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}, Spec: spec, Status: status}
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{targetIndex: indexByTarget})
+	for _, vsc := range []*v1alpha1.VaultSecretClaim{
+		claim("postgres", v1alpha1.VaultSecretClaimSpec{}, conflict),
+		claim("redis", v1alpha1.VaultSecretClaimSpec{}, conflict),
+		claim("replica", v1alpha1.VaultSecretClaimSpec{Secrets: []v1alpha1.SecretTemplate{{Name: "postgres"}}}, v1alpha1.VaultSecretClaimStatus{}),
+		claim("templated", v1alpha1.VaultSecretClaimSpec{TemplateRef: &v1alpha1.TemplateReference{Name: "db"}}, conflict),
+	} {
+		indexer.Add(vsc)
+	}
+
+	tests := []struct {
+		secret string
+		want   []string
+	}{
+		{"postgres", []string{"default/postgres", "default/templated"}},
+		{"postgres-0a1b2c3d", []string{"default/postgres", "default/templated"}},
+		{"postgres-replica", []string{"default/templated"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			c := newTestController(nil)
+			defer c.queue.ShutDown()
+			c.vscIndexer = indexer
+
+			c.enqueueConflictingClaims(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: tt.secret}})
+
+			var got []string
+			for c.queue.Len() > 0 {
+				key, _ := c.queue.Get()
+				got = append(got, key.(string))
+				c.queue.Done(key)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got claims %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
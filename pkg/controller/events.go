@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// controllerAgentName is the name of the component reported in events.
+const controllerAgentName = "dweller"
+
+// Reasons of vault secret claim events.
+const (
+	eventSecretCreated    = "SecretCreated"
+	eventSecretUpdated    = "SecretUpdated"
+	eventConflict         = "Conflict"
+	eventVaultReadFailed  = "VaultReadFailed"
+	eventPermissionDenied = "PermissionDenied"
+	eventPolicyDenied     = "PolicyDenied"
+	eventRetriesExhausted = "RetriesExhausted"
+	eventInvalidSpec      = "InvalidSpec"
+	eventRolloutTriggered = "RolloutTriggered"
+)
+
+// eventCache remembers the last events emitted for vault secret claims to not
+// to repeat them on every resync.
+type eventCache struct {
+	mu sync.Mutex
+
+	// events maps claim key to the last events by their reason and subject.
+	events map[string]map[string]emittedEvent
+}
+
+type emittedEvent struct {
+	eventType string
+	message   string
+}
+
+func newEventCache() *eventCache {
+	return &eventCache{events: make(map[string]map[string]emittedEvent)}
+}
+
+// seen reports whether the same event has been emitted for the claim the last
+// time. Otherwise it remembers the event.
+func (ec *eventCache) seen(key, subject string, event emittedEvent) bool {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	events, ok := ec.events[key]
+	if !ok {
+		events = make(map[string]emittedEvent)
+		ec.events[key] = events
+	}
+
+	if events[subject] == event {
+		return true
+	}
+	events[subject] = event
+	return false
+}
+
+// forgetWarnings forgets warnings emitted for the claim, so that they are
+// emitted again if the problem recurs.
+func (ec *eventCache) forgetWarnings(key string) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	for subject, event := range ec.events[key] {
+		if event.eventType == corev1.EventTypeWarning {
+			delete(ec.events[key], subject)
+		}
+	}
+}
+
+// forget forgets all the events emitted for the claim.
+func (ec *eventCache) forget(key string) {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+
+	delete(ec.events, key)
+}
+
+// event emits the event for vault secret claim or cluster vault secret claim
+// unless the same one has been emitted before. Events are told apart by their
+// reason and subject, e.g. the secret or the data item name.
+func (c *Controller) event(claim runtime.Object, eventType, reason, subject, messageFmt string, args ...interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(claim)
+	if err != nil {
+		return
+	}
+
+	message := fmt.Sprintf(messageFmt, args...)
+	if c.events.seen(key, reason+"/"+subject, emittedEvent{eventType: eventType, message: message}) {
+		return
+	}
+	c.recorder.Event(claim, eventType, reason, message)
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// healthPollInterval is the interval of checking the secret provider health
+// while syncing is paused.
+const healthPollInterval = time.Second
+
+// WithHealth makes the controller pause syncing while the secret provider is
+// not healthy. Vault secret claims that failed during the outage are synced
+// again once it recovers.
+func WithHealth(health secret.Health) Option {
+	return func(c *Controller) {
+		c.health = health
+	}
+}
+
+// alwaysHealthy is the health of the secret provider that is not monitored.
+type alwaysHealthy struct{}
+
+func (alwaysHealthy) Healthy() bool {
+	return true
+}
+
+// waitHealthy blocks while the secret provider is not healthy. It reports
+// false if the queue is shutting down.
+func (c *Controller) waitHealthy() bool {
+	for !c.health.Healthy() {
+		if c.queue.ShuttingDown() {
+			return false
+		}
+		time.Sleep(healthPollInterval)
+	}
+	return true
+}
+
+// watchHealth requeues parked keys once the secret provider recovers.
+func (c *Controller) watchHealth(stopCh <-chan struct{}) {
+	healthy := true
+	wait.Until(func() {
+		current := c.health.Healthy()
+		switch {
+		case healthy && !current:
+			c.logger.Warnf("Secret provider is not healthy, syncing is paused")
+		case !healthy && current:
+			keys := c.parked.takeOutage()
+			c.logger.Infof("Secret provider is healthy again, requeueing %d VaultSecretClaims", len(keys))
+			for _, key := range keys {
+				c.queue.Add(key)
+			}
+		}
+		healthy = current
+	}, healthPollInterval, stopCh)
+}
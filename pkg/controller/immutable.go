@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+const (
+	// claimLabel is set on immutable secrets to the name of vault secret claim
+	// that produced them.
+	claimLabel = "dweller.io/vault-secret-claim"
+
+	// targetLabel is set on immutable secrets to the name of secret template
+	// they were produced from.
+	targetLabel = "dweller.io/target-secret"
+
+	// defaultRevisionHistoryLimit is the number of old immutable secrets
+	// retained if vault secret claim does not specify it.
+	defaultRevisionHistoryLimit = 2
+)
+
+// syncImmutableSecret syncs the assembled secret in immutable mode: every
+// distinct content produces a new immutable secret named "<target>-<hash>".
+// The secret is renamed accordingly. Conflict is reported if there is a secret
+// with the same name not owned by the claim.
+func (c *Controller) syncImmutableSecret(vsc *v1alpha1.VaultSecretClaim, sec *corev1.Secret) error {
+	lbls := make(map[string]string, len(sec.Labels)+2)
+	for k, v := range sec.Labels {
+		lbls[k] = v
+	}
+	lbls[claimLabel] = vsc.Name
+	lbls[targetLabel] = sec.Name
+	sec.Labels = lbls
+
+	sec.Name = fmt.Sprintf("%s-%s", sec.Name, secret.Hash(sec))
+
+	c.logger.Debugf("Looking for immutable Secret \"%s/%s\"", sec.Namespace, sec.Name)
+	existing, err := c.secretLister.Secrets(sec.Namespace).Get(sec.Name)
+	switch {
+	case apierrors.IsNotFound(err):
+		if err := c.createImmutableSecret(sec); err != nil {
+			return err
+		}
+		c.logger.Infof("Immutable Secret \"%s/%s\" has been created", sec.Namespace, sec.Name)
+		c.recorder.Eventf(vsc, corev1.EventTypeNormal, eventSecretCreated, "Immutable secret %q has been created", sec.Name)
+	case err != nil:
+		return err
+	case !metav1.IsControlledBy(existing, vsc):
+		return c.reportConflict(vsc, "secret", existing)
+	}
+
+	return nil
+}
+
+// createImmutableSecret creates the secret with immutable flag set.
+func (c *Controller) createImmutableSecret(sec *corev1.Secret) error {
+	// The client we use does not know about immutable secrets, so the secret
+	// is sent as a raw object with the flag added.
+	sec.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	encoded, err := json.Marshal(sec)
+	if err != nil {
+		return fmt.Errorf("encode kubernetes secret: %v", err)
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(encoded, &obj); err != nil {
+		return fmt.Errorf("encode kubernetes secret: %v", err)
+	}
+	obj["immutable"] = true
+
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("encode kubernetes secret: %v", err)
+	}
+
+	err = c.client.CoreV1().RESTClient().Post().
+		Namespace(sec.Namespace).
+		Resource("secrets").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do().
+		Error()
+	if apierrors.IsAlreadyExists(err) {
+		// Informer cache is not up to date yet, ownership will be checked on
+		// the next sync.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create kubernetes secret: %v", err)
+	}
+	metrics.Add(metricSecretWrites, 1)
+
+	return nil
+}
+
+// retainedImmutableSecrets returns names of old immutable secrets of vault
+// secret claim within its revision history limit. The limit applies to every
+// secret template separately.
+func retainedImmutableSecrets(vsc *v1alpha1.VaultSecretClaim, owned map[string]*corev1.Secret, current []v1alpha1.SecretStatus) []string {
+	limit := defaultRevisionHistoryLimit
+	if vsc.Spec.RevisionHistoryLimit != nil {
+		limit = int(*vsc.Spec.RevisionHistoryLimit)
+	}
+
+	var retained []string
+	for _, status := range current {
+		var old []*corev1.Secret
+		for name, sec := range owned {
+			if name != status.Name && sec.Labels[targetLabel] == status.Target {
+				old = append(old, sec)
+			}
+		}
+
+		// Newest go first.
+		sort.Slice(old, func(i, j int) bool {
+			return old[i].CreationTimestamp.After(old[j].CreationTimestamp.Time)
+		})
+
+		for i := 0; i < len(old) && i < limit; i++ {
+			retained = append(retained, old[i].Name)
+		}
+	}
+
+	return retained
+}
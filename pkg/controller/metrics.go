@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"expvar"
+)
+
+// metrics of the controller are exported by expvar, e.g. at /debug/vars.
+var metrics = expvar.NewMap("dweller")
+
+const (
+	// metricSecretWrites counts secrets written to the kubernetes API.
+	metricSecretWrites = "secret_writes"
+
+	// metricSecretWritesSkipped counts secret updates skipped since they were
+	// already up to date.
+	metricSecretWritesSkipped = "secret_writes_skipped"
+
+	// metricConfigMapWrites counts config maps written to the kubernetes API.
+	metricConfigMapWrites = "configmap_writes"
+
+	// metricConfigMapWritesSkipped counts config map updates skipped since
+	// they were already up to date.
+	metricConfigMapWritesSkipped = "configmap_writes_skipped"
+
+	// metricParkedClaims is the number of parked vault secret claims.
+	metricParkedClaims = "parked_claims"
+
+	// metricParkedClaimKeys lists keys of parked vault secret claims.
+	metricParkedClaimKeys = "parked_claim_keys"
+
+	// metricRollouts counts rollouts of workloads triggered by changes of
+	// secrets they depend on.
+	metricRollouts = "rollouts"
+)
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// reasonPolicyDenied is the reason of the Synced condition of vault secret
+// claims that vault path policies don't allow to read some of their paths.
+const reasonPolicyDenied = "PolicyDenied"
+
+// WithPathPolicies makes the controller retry vault secret claims denied by
+// vault path policies as soon as the policies of the informer are added or
+// changed, rather than on the parked interval. The informer must be the one
+// the policies are enforced with, so that its cache is up to date by the time
+// the claims are synced. Secrets of cluster vault secret claims are written
+// only to namespaces the authorizer allows to read all of their paths.
+func WithPathPolicies(authorizer secret.PathAuthorizer, informer cache.SharedInformer) Option {
+	return func(c *Controller) {
+		c.pathAuthorizer = authorizer
+		c.pathPolicyInformer = informer
+	}
+}
+
+// setUpPathPolicies sets up handlers of vault path policies.
+func (c *Controller) setUpPathPolicies() {
+	c.pathPolicyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueuePolicyDeniedClaims()
+		},
+		UpdateFunc: func(old, new interface{}) {
+			oldVpp := old.(*v1alpha1.VaultPathPolicy)
+			newVpp := new.(*v1alpha1.VaultPathPolicy)
+			if oldVpp.ResourceVersion == newVpp.ResourceVersion {
+				// Periodic resync sends the same policy.
+				return
+			}
+			c.enqueuePolicyDeniedClaims()
+		},
+		// Deleted policies can't allow anything.
+	})
+}
+
+// enqueuePolicyDeniedClaims enqueues vault secret claims last failed to sync
+// because of vault path policies, and cluster vault secret claims denied in
+// some namespace.
+func (c *Controller) enqueuePolicyDeniedClaims() {
+	claims, err := c.vscLister.List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Couldn't list VaultSecretClaims: %v", err)
+		return
+	}
+
+	for _, vsc := range claims {
+		cond := getCondition(vsc.Status.Conditions, v1alpha1.ClaimSynced)
+		if cond != nil && cond.Reason == reasonPolicyDenied {
+			c.logger.Debugf("Vault path policies of VaultSecretClaim \"%s/%s\" have changed", vsc.Namespace, vsc.Name)
+			c.enqueue(vsc)
+		}
+	}
+
+	if c.clusterQueue == nil {
+		return
+	}
+	clusterClaims, err := c.clusterClaimLister.List(labels.Everything())
+	if err != nil {
+		c.logger.Errorf("Couldn't list ClusterVaultSecretClaims: %v", err)
+		return
+	}
+	for _, cvsc := range clusterClaims {
+		for _, status := range cvsc.Status.Namespaces {
+			if status.Reason == reasonPolicyDenied {
+				c.logger.Debugf("Vault path policies of ClusterVaultSecretClaim %q have changed", cvsc.Name)
+				c.clusterQueue.Add(cvsc.Name)
+				break
+			}
+		}
+	}
+}
+
+// authorizeClusterNamespace checks that vault path policies allow claims of
+// the namespace to read every path of cluster vault secret claim, since its
+// secret written there is readable by the namespace.
+func (c *Controller) authorizeClusterNamespace(cvsc *v1alpha1.ClusterVaultSecretClaim, namespace string) *secret.ItemError {
+	if c.pathAuthorizer == nil {
+		return nil
+	}
+	for _, item := range cvsc.Spec.Secret.Data {
+		if err := c.pathAuthorizer.Authorize(namespace, item.VaultPath, item.Engine); err != nil {
+			return &secret.ItemError{Key: item.Key, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/ratelimit"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// RetryPolicy defines how failed syncs of vault secret claims are retried.
+// A claim is retried with exponential backoff and parked after too many
+// retries. Parked claims are re-attempted on a slower cadence or when related
+// objects change, until they are synced successfully.
+type RetryPolicy struct {
+	// BaseDelay is the delay of the first retry, it doubles with every retry.
+	BaseDelay time.Duration
+
+	// MaxDelay is the ceiling of the retry delay.
+	MaxDelay time.Duration
+
+	// MaxRetries is the number of retries before the claim is parked.
+	MaxRetries int
+
+	// ParkedInterval is the interval of re-attempting parked claims.
+	ParkedInterval time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used unless another one is set.
+var DefaultRetryPolicy = RetryPolicy{
+	BaseDelay:      time.Second,
+	MaxDelay:       5 * time.Minute,
+	MaxRetries:     8,
+	ParkedInterval: 10 * time.Minute,
+}
+
+// WithRetryPolicy sets the policy of retrying failed syncs of vault secret
+// claims.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Controller) {
+		c.retry = policy
+	}
+}
+
+// rateLimiter returns the rate limiter of retries. Besides the per-claim
+// backoff, the overall rate of retries is limited like by the default rate
+// limiter of client-go, so that mass failures don't flood the API server.
+func (p RetryPolicy) rateLimiter() workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(p.BaseDelay, p.MaxDelay),
+		&workqueue.BucketRateLimiter{Bucket: ratelimit.NewBucketWithRate(float64(10), int64(100))},
+	)
+}
+
+// parkedKeys is a set of keys of vault secret claims that are failing
+// permanently or failed during the secret provider outage.
+type parkedKeys struct {
+	mu sync.Mutex
+
+	// keys maps parked keys to whether they were parked due to the outage.
+	keys map[string]bool
+}
+
+func newParkedKeys() *parkedKeys {
+	return &parkedKeys{keys: make(map[string]bool)}
+}
+
+func (pk *parkedKeys) add(key string, outage bool) {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	pk.keys[key] = outage
+}
+
+func (pk *parkedKeys) has(key string) bool {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	_, ok := pk.keys[key]
+	return ok
+}
+
+// remove removes the key from the set. It reports whether the key was parked.
+func (pk *parkedKeys) remove(key string) bool {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	_, ok := pk.keys[key]
+	delete(pk.keys, key)
+	return ok
+}
+
+// takeOutage removes keys parked due to the outage from the set and returns
+// them.
+func (pk *parkedKeys) takeOutage() []string {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	var keys []string
+	for key, outage := range pk.keys {
+		if outage {
+			keys = append(keys, key)
+			delete(pk.keys, key)
+		}
+	}
+	return keys
+}
+
+// list returns all the parked keys sorted.
+func (pk *parkedKeys) list() []string {
+	pk.mu.Lock()
+	defer pk.mu.Unlock()
+
+	keys := make([]string, 0, len(pk.keys))
+	for key := range pk.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// exportParkedKeys exports parked keys of the controller to metrics.
+func (c *Controller) exportParkedKeys() {
+	metrics.Set(metricParkedClaims, expvar.Func(func() interface{} {
+		return len(c.parked.list())
+	}))
+	metrics.Set(metricParkedClaimKeys, expvar.Func(func() interface{} {
+		return c.parked.list()
+	}))
+}
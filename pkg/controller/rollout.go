@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+const (
+	// rolloutAnnotation is set on deployments, stateful sets and daemon sets
+	// to a comma separated list of names of vault secret claims they depend
+	// on. The workloads are rolled out whenever secrets of the claims change.
+	rolloutAnnotation = "dweller.io/rollout-on-change"
+
+	// checksumAnnotationPrefix prefixes the name of the secret in pod template
+	// annotations of dependent workloads. The annotation value is the content
+	// hash of the secret, so changing it triggers a rolling restart.
+	checksumAnnotationPrefix = "checksum.dweller.io/"
+)
+
+// Kinds of workloads that can depend on vault secret claims.
+const (
+	kindDeployment  = "Deployment"
+	kindStatefulSet = "StatefulSet"
+	kindDaemonSet   = "DaemonSet"
+)
+
+// workloadResources maps kinds of workloads to their resources.
+var workloadResources = map[string]string{
+	kindDeployment:  "deployments",
+	kindStatefulSet: "statefulsets",
+	kindDaemonSet:   "daemonsets",
+}
+
+// kindClaimDependents marks rollouts queued for a vault secret claim, which
+// are to be rolled out to workloads depending on it once they are listed.
+const kindClaimDependents = "dependents of VaultSecretClaim"
+
+// RolloutPolicy limits the rate of rollouts of workloads depending on vault
+// secret claims, so that rotating many secrets at once doesn't cause a restart
+// storm.
+type RolloutPolicy struct {
+	// QPS is the number of rollouts triggered per second.
+	QPS float32
+
+	// Burst is the number of rollouts triggered at once.
+	Burst int
+}
+
+// DefaultRolloutPolicy is the rollout policy used unless another one is set.
+var DefaultRolloutPolicy = RolloutPolicy{
+	QPS:   0.1,
+	Burst: 5,
+}
+
+// WithRolloutPolicy sets the policy of rolling out workloads depending on
+// vault secret claims.
+func WithRolloutPolicy(policy RolloutPolicy) Option {
+	return func(c *Controller) {
+		c.rollout = policy
+	}
+}
+
+// workload identifies a deployment, stateful set or daemon set, or the vault
+// secret claim whose dependents are to be rolled out.
+type workload struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func (w workload) String() string {
+	return fmt.Sprintf("%s \"%s/%s\"", w.kind, w.namespace, w.name)
+}
+
+// pendingRollouts accumulates content hashes of changed secrets to be set on
+// workloads, so that several secrets changed at once trigger a single rollout.
+type pendingRollouts struct {
+	mu sync.Mutex
+
+	// checksums maps workloads to content hashes by secret name.
+	checksums map[workload]map[string]string
+}
+
+func newPendingRollouts() *pendingRollouts {
+	return &pendingRollouts{checksums: make(map[workload]map[string]string)}
+}
+
+func (pr *pendingRollouts) add(w workload, secret, hash string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.checksums[w] == nil {
+		pr.checksums[w] = make(map[string]string)
+	}
+	pr.checksums[w][secret] = hash
+}
+
+// restore returns checksums of the failed rollout back unless newer ones were
+// added meanwhile.
+func (pr *pendingRollouts) restore(w workload, checksums map[string]string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if pr.checksums[w] == nil {
+		pr.checksums[w] = make(map[string]string)
+	}
+	for secret, hash := range checksums {
+		if _, ok := pr.checksums[w][secret]; !ok {
+			pr.checksums[w][secret] = hash
+		}
+	}
+}
+
+// take removes checksums of the workload and returns them.
+func (pr *pendingRollouts) take(w workload) map[string]string {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	checksums := pr.checksums[w]
+	delete(pr.checksums, w)
+	return checksums
+}
+
+// dependsOn reports whether the workload annotations list the vault secret
+// claim.
+func dependsOn(annotations map[string]string, claim string) bool {
+	for _, name := range strings.Split(annotations[rolloutAnnotation], ",") {
+		if strings.TrimSpace(name) == claim {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloutDependents schedules rollouts of workloads depending on vault secret
+// claim after the content of its secret has changed. The workloads are listed
+// by the rollout worker, so that the listing is retried if it fails: the
+// secret is already updated, so the next sync wouldn't roll them out.
+func (c *Controller) rolloutDependents(vsc *v1alpha1.VaultSecretClaim, secret, hash string) {
+	w := workload{kind: kindClaimDependents, namespace: vsc.Namespace, name: vsc.Name}
+	c.pendingRollouts.add(w, secret, hash)
+	c.rollouts.Add(w)
+}
+
+// scheduleDependents schedules rollouts of workloads depending on the vault
+// secret claim with the checksums of its changed secrets.
+func (c *Controller) scheduleDependents(claim workload, checksums map[string]string) error {
+	workloads, err := c.dependentWorkloads(claim.namespace, claim.name)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range workloads {
+		for secret, hash := range checksums {
+			c.logger.Infof("Scheduling rollout of %s after Secret \"%s/%s\" has changed", w, w.namespace, secret)
+			c.pendingRollouts.add(w, secret, hash)
+		}
+		c.rollouts.Add(w)
+	}
+	return nil
+}
+
+// dependentWorkloads returns workloads of the namespace depending on the vault
+// secret claim. Changes of secrets are rare, so workloads are listed from the
+// kubernetes API rather than watched.
+func (c *Controller) dependentWorkloads(namespace, claim string) ([]workload, error) {
+	var workloads []workload
+	for _, kind := range []string{kindDeployment, kindStatefulSet, kindDaemonSet} {
+		items, err := c.apps.list(workloadResources[kind], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %v", workloadResources[kind], err)
+		}
+		for _, meta := range items {
+			if dependsOn(meta.Annotations, claim) {
+				workloads = append(workloads, workload{kind: kind, namespace: meta.Namespace, name: meta.Name})
+			}
+		}
+	}
+	return workloads, nil
+}
+
+func (c *Controller) runRolloutWorker() {
+	for c.processNextRollout() {
+	}
+}
+
+func (c *Controller) processNextRollout() bool {
+	item, quit := c.rollouts.Get()
+	if quit {
+		return false
+	}
+	defer c.rollouts.Done(item)
+
+	w := item.(workload)
+	checksums := c.pendingRollouts.take(w)
+	if len(checksums) == 0 {
+		c.rollouts.Forget(item)
+		return true
+	}
+
+	var err error
+	if w.kind == kindClaimDependents {
+		err = c.scheduleDependents(w, checksums)
+	} else {
+		// Rollouts are paced to avoid restart storms.
+		c.rolloutLimiter.Accept()
+		err = c.rolloutWorkload(w, checksums)
+	}
+
+	if apierrors.IsNotFound(err) {
+		// Workloads are not found once the cluster stops serving the apps
+		// version in use, so it's discovered again.
+		c.apps.reset()
+	}
+
+	switch {
+	case err == nil:
+		c.rollouts.Forget(item)
+	case apierrors.IsNotFound(err) && w.kind != kindClaimDependents && c.workloadGone(w):
+		c.logger.Infof("Skipping rollout of %s, it's gone", w)
+		c.rollouts.Forget(item)
+	case c.rollouts.NumRequeues(item) < c.retry.MaxRetries:
+		c.logger.Errorf("Error rolling out %s (will retry): %v", w, err)
+		c.pendingRollouts.restore(w, checksums)
+		c.rollouts.AddRateLimited(item)
+	default:
+		c.logger.Errorf("Dropping rollout of %s out of the queue: %v", w, err)
+		c.rollouts.Forget(item)
+	}
+	return true
+}
+
+// rolloutWorkload sets content hashes of changed secrets in pod template
+// annotations of the workload, which triggers its rolling restart.
+func (c *Controller) rolloutWorkload(w workload, checksums map[string]string) error {
+	annotations := make(map[string]string, len(checksums))
+	for secret, hash := range checksums {
+		annotations[checksumAnnotationPrefix+secret] = hash
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": annotations,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encode patch: %v", err)
+	}
+
+	resource, ok := workloadResources[w.kind]
+	if !ok {
+		return fmt.Errorf("unknown workload kind %q", w.kind)
+	}
+	ref, err := c.apps.patch(w, resource, patch)
+	if err != nil {
+		return err
+	}
+
+	metrics.Add(metricRollouts, 1)
+	c.logger.Infof("%s has been rolled out", w)
+	c.recorder.Eventf(ref, corev1.EventTypeNormal, eventRolloutTriggered, "Rolled out after secrets have changed: %s", formatKeys(checksums))
+	return nil
+}
+
+// workloadGone reports whether the workload is missing from the list of its
+// kind, as opposed to the whole kind being not found.
+func (c *Controller) workloadGone(w workload) bool {
+	items, err := c.apps.list(workloadResources[w.kind], w.namespace)
+	if err != nil {
+		return false
+	}
+	for _, meta := range items {
+		if meta.Name == w.name {
+			return false
+		}
+	}
+	return true
+}
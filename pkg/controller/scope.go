@@ -0,0 +1,370 @@
+package controller
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	dwellerlisters "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+)
+
+// Scope restricts objects watched by the controller, so that it can run with
+// namespace-scoped permissions only.
+type Scope struct {
+	// Namespaces is a list of watched namespaces.
+	Namespaces []string
+
+	// NamespaceSelector selects watched namespaces by their labels. It
+	// requires permissions to list and watch namespaces.
+	NamespaceSelector labels.Selector
+
+	// ClaimSelector selects watched vault secret claims by their labels.
+	ClaimSelector labels.Selector
+}
+
+// WithScope restricts objects watched by the controller. All the namespaces
+// are watched by default. Namespaces and namespace selector are mutually
+// exclusive.
+func WithScope(scope Scope) Option {
+	return func(c *Controller) {
+		c.scope = scope
+	}
+}
+
+// allNamespaces reports whether the scope is not restricted to some
+// namespaces.
+func (s Scope) allNamespaces() bool {
+	return len(s.Namespaces) == 0 && s.NamespaceSelector == nil
+}
+
+// claimSelector returns the selector of watched vault secret claims.
+func (s Scope) claimSelector() labels.Selector {
+	if s.ClaimSelector == nil {
+		return labels.Everything()
+	}
+	return s.ClaimSelector
+}
+
+// namespaceInformers are informers of objects of a single watched namespace,
+// or all the namespaces if the scope is not restricted.
+type namespaceInformers struct {
+	secrets    cache.SharedIndexInformer
+	configMaps cache.SharedIndexInformer
+	claims     cache.SharedIndexInformer
+
+	// stopCh stops the informers when the namespace is not watched anymore.
+	stopCh chan struct{}
+}
+
+func (ni *namespaceInformers) run() {
+	go ni.secrets.Run(ni.stopCh)
+	go ni.configMaps.Run(ni.stopCh)
+	go ni.claims.Run(ni.stopCh)
+}
+
+func (ni *namespaceInformers) hasSynced() bool {
+	return ni.secrets.HasSynced() && ni.configMaps.HasSynced() && ni.claims.HasSynced()
+}
+
+// namespaceSet is a set of informers of watched namespaces.
+type namespaceSet struct {
+	mu         sync.RWMutex
+	namespaces map[string]*namespaceInformers
+
+	// empty are informers that are never run, they stand in for namespaces
+	// that are not watched.
+	empty *namespaceInformers
+
+	// started reports whether informers of namespaces are run as soon as the
+	// namespaces are watched.
+	started bool
+}
+
+// get returns informers of the namespace. Informers of all the namespaces are
+// returned if there are ones.
+func (ns *namespaceSet) get(namespace string) *namespaceInformers {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	if ni, ok := ns.namespaces[metav1.NamespaceAll]; ok {
+		return ni
+	}
+	if ni, ok := ns.namespaces[namespace]; ok {
+		return ni
+	}
+	return ns.empty
+}
+
+// watched reports whether objects of the namespace are watched.
+func (ns *namespaceSet) watched(namespace string) bool {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	_, all := ns.namespaces[metav1.NamespaceAll]
+	_, ok := ns.namespaces[namespace]
+	return all || ok
+}
+
+// all returns informers of all the watched namespaces.
+func (ns *namespaceSet) all() []*namespaceInformers {
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+
+	result := make([]*namespaceInformers, 0, len(ns.namespaces))
+	for _, ni := range ns.namespaces {
+		result = append(result, ni)
+	}
+	return result
+}
+
+// newNamespaceInformers returns informers of objects of the namespace with the
+// controller indexes and event handlers.
+func (c *Controller) newNamespaceInformers(namespace string) *namespaceInformers {
+	ni := &namespaceInformers{
+		secrets: coreinformers.NewSecretInformer(c.client, namespace, builtinResync, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			ownerIndex:           indexByOwner,
+			contributorIndex:     indexByContributor,
+		}),
+		configMaps: coreinformers.NewConfigMapInformer(c.client, namespace, builtinResync, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		}),
+		claims: c.newClaimInformer(namespace, cache.Indexers{
+			cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			templateIndex:        indexByTemplate,
+			targetIndex:          indexByTarget,
+		}),
+		stopCh: make(chan struct{}),
+	}
+
+	ni.secrets.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addManagedSecret,
+		UpdateFunc: c.updateManagedSecret,
+		DeleteFunc: c.deleteManagedSecret,
+	})
+	ni.claims.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addVaultSecretClaim,
+		UpdateFunc: c.updateVaultSecretClaim,
+		DeleteFunc: c.deleteVaultSecretClaim,
+	})
+
+	return ni
+}
+
+// newClaimInformer returns the informer of vault secret claims of the namespace
+// selected by the scope claim selector.
+func (c *Controller) newClaimInformer(namespace string, indexers cache.Indexers) cache.SharedIndexInformer {
+	selector := c.scope.claimSelector().String()
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = selector
+				return c.clientset.DwellerV1alpha1().VaultSecretClaims(namespace).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = selector
+				return c.clientset.DwellerV1alpha1().VaultSecretClaims(namespace).Watch(options)
+			},
+		},
+		&v1alpha1.VaultSecretClaim{},
+		customResync,
+		indexers,
+	)
+}
+
+// newNamespaceInformer returns the informer of namespaces selected by the scope
+// namespace selector.
+func (c *Controller) newNamespaceInformer() cache.SharedIndexInformer {
+	informer := c.newSelectedNamespaceInformer(c.scope.NamespaceSelector)
+
+	// Namespaces that stop matching the selector are reported as deleted.
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.watchNamespace(obj.(*corev1.Namespace).Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			ns, ok := obj.(*corev1.Namespace)
+			if !ok {
+				c.logger.Errorf("Couldn't get Namespace from %#v", obj)
+				return
+			}
+			c.unwatchNamespace(ns.Name)
+		},
+	})
+
+	return informer
+}
+
+// newSelectedNamespaceInformer returns the informer of namespaces selected by
+// the selector.
+func (c *Controller) newSelectedNamespaceInformer(sel labels.Selector) cache.SharedIndexInformer {
+	selector := sel.String()
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = selector
+				return c.client.CoreV1().Namespaces().List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = selector
+				return c.client.CoreV1().Namespaces().Watch(options)
+			},
+		},
+		&corev1.Namespace{},
+		builtinResync,
+		cache.Indexers{},
+	)
+}
+
+// watchNamespace starts watching objects of the namespace. Informers are run
+// right away if the controller is already started.
+func (c *Controller) watchNamespace(namespace string) *namespaceInformers {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+
+	if ni, ok := c.namespaces.namespaces[namespace]; ok {
+		return ni
+	}
+
+	if namespace != metav1.NamespaceAll {
+		c.logger.Infof("Watching namespace %q", namespace)
+	}
+	ni := c.newNamespaceInformers(namespace)
+	c.namespaces.namespaces[namespace] = ni
+	if c.namespaces.started {
+		ni.run()
+	}
+	return ni
+}
+
+// unwatchNamespace stops watching objects of the namespace.
+func (c *Controller) unwatchNamespace(namespace string) {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+
+	ni, ok := c.namespaces.namespaces[namespace]
+	if !ok {
+		return
+	}
+
+	c.logger.Infof("Not watching namespace %q anymore", namespace)
+	close(ni.stopCh)
+	delete(c.namespaces.namespaces, namespace)
+}
+
+// startNamespaces runs informers of all the watched namespaces and makes the
+// informers of namespaces watched later run right away.
+func (c *Controller) startNamespaces() []cache.InformerSynced {
+	c.namespaces.mu.Lock()
+	defer c.namespaces.mu.Unlock()
+
+	var synced []cache.InformerSynced
+	for _, ni := range c.namespaces.namespaces {
+		if !c.namespaces.started {
+			ni.run()
+		}
+		synced = append(synced, ni.hasSynced)
+	}
+	c.namespaces.started = true
+	return synced
+}
+
+// scopedSecretLister lists secrets of the watched namespaces.
+type scopedSecretLister struct {
+	namespaces *namespaceSet
+}
+
+func (l scopedSecretLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	var result []*corev1.Secret
+	for _, ni := range l.namespaces.all() {
+		secrets, err := corelisters.NewSecretLister(ni.secrets.GetIndexer()).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, secrets...)
+	}
+	return result, nil
+}
+
+func (l scopedSecretLister) Secrets(namespace string) corelisters.SecretNamespaceLister {
+	ni := l.namespaces.get(namespace)
+	return corelisters.NewSecretLister(ni.secrets.GetIndexer()).Secrets(namespace)
+}
+
+// scopedConfigMapLister lists config maps of the watched namespaces.
+type scopedConfigMapLister struct {
+	namespaces *namespaceSet
+}
+
+func (l scopedConfigMapLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	var result []*corev1.ConfigMap
+	for _, ni := range l.namespaces.all() {
+		configMaps, err := corelisters.NewConfigMapLister(ni.configMaps.GetIndexer()).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, configMaps...)
+	}
+	return result, nil
+}
+
+func (l scopedConfigMapLister) ConfigMaps(namespace string) corelisters.ConfigMapNamespaceLister {
+	ni := l.namespaces.get(namespace)
+	return corelisters.NewConfigMapLister(ni.configMaps.GetIndexer()).ConfigMaps(namespace)
+}
+
+// scopedClaimLister lists vault secret claims of the watched namespaces.
+type scopedClaimLister struct {
+	namespaces *namespaceSet
+}
+
+func (l scopedClaimLister) List(selector labels.Selector) ([]*v1alpha1.VaultSecretClaim, error) {
+	var result []*v1alpha1.VaultSecretClaim
+	for _, ni := range l.namespaces.all() {
+		claims, err := dwellerlisters.NewVaultSecretClaimLister(ni.claims.GetIndexer()).List(selector)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, claims...)
+	}
+	return result, nil
+}
+
+func (l scopedClaimLister) VaultSecretClaims(namespace string) dwellerlisters.VaultSecretClaimNamespaceLister {
+	ni := l.namespaces.get(namespace)
+	return dwellerlisters.NewVaultSecretClaimLister(ni.claims.GetIndexer()).VaultSecretClaims(namespace)
+}
+
+// byIndexer returns objects matching the indexed value.
+type byIndexer interface {
+	ByIndex(indexName, indexedValue string) ([]interface{}, error)
+}
+
+// scopedIndexer looks objects up by index in all the watched namespaces.
+type scopedIndexer struct {
+	namespaces *namespaceSet
+	informer   func(*namespaceInformers) cache.SharedIndexInformer
+}
+
+func (si scopedIndexer) ByIndex(indexName, indexedValue string) ([]interface{}, error) {
+	var result []interface{}
+	for _, ni := range si.namespaces.all() {
+		objs, err := si.informer(ni).GetIndexer().ByIndex(indexName, indexedValue)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, objs...)
+	}
+	return result, nil
+}
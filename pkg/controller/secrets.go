@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// Secrets are watched to revert manual changes of managed secrets as soon as
+// possible instead of waiting for the claim resync.
+
+// targetIndex is the name of vault secret claim informer index by key of the
+// secrets they produce, "<namespace>/<name>", and of cluster vault secret
+// claim informer index by name of the secret. Claims rendering their secret
+// from a template are indexed by the secrets of the last sync, and by the
+// namespace key with an empty name until then, since the names are not known.
+const targetIndex = "target"
+
+// indexByTarget indexes vault secret claims by keys of the secrets they
+// produce.
+func indexByTarget(obj interface{}) ([]string, error) {
+	vsc, ok := obj.(*v1alpha1.VaultSecretClaim)
+	if !ok {
+		return nil, nil
+	}
+
+	prefix := vsc.Namespace + "/"
+	if vsc.Spec.TemplateRef != nil {
+		if len(vsc.Status.Secrets) == 0 {
+			return []string{prefix}, nil
+		}
+		keys := make([]string, 0, len(vsc.Status.Secrets))
+		for _, status := range vsc.Status.Secrets {
+			keys = append(keys, prefix+status.Target)
+		}
+		return keys, nil
+	}
+
+	templates := vsc.SecretTemplates()
+	keys := make([]string, 0, len(templates))
+	for _, tmpl := range templates {
+		keys = append(keys, prefix+tmpl.Name)
+	}
+	return keys, nil
+}
+
+// indexClusterClaimByTarget indexes cluster vault secret claims by name of the
+// secret they write to the namespaces.
+func indexClusterClaimByTarget(obj interface{}) ([]string, error) {
+	cvsc, ok := obj.(*v1alpha1.ClusterVaultSecretClaim)
+	if !ok {
+		return nil, nil
+	}
+	if cvsc.Spec.Secret.Name != "" {
+		return []string{cvsc.Spec.Secret.Name}, nil
+	}
+	return []string{cvsc.Name}, nil
+}
+
+// targetNames returns names of the secret templates the secret with the given
+// name can be produced from: the name itself, and the name without the
+// content hash in immutable mode.
+func targetNames(name string) []string {
+	names := []string{name}
+	if i := strings.LastIndex(name, "-"); i > 0 {
+		hash := name[i+1:]
+		if len(hash) == 8 && strings.Trim(hash, "0123456789abcdef") == "" {
+			names = append(names, name[:i])
+		}
+	}
+	return names
+}
+
+func (c *Controller) addManagedSecret(obj interface{}) {
+	sec := obj.(*corev1.Secret)
+	c.enqueueSecretClaims(sec)
+}
+
+func (c *Controller) updateManagedSecret(old, new interface{}) {
+	oldSec := old.(*corev1.Secret)
+	newSec := new.(*corev1.Secret)
+	if oldSec.ResourceVersion == newSec.ResourceVersion {
+		// Periodic resync sends the same secret, claims are resynced on their
+		// own.
+		return
+	}
+
+	// Owners might have been changed, so the former ones are notified as well.
+	c.enqueueSecretClaims(oldSec)
+	c.enqueueSecretClaims(newSec)
+}
+
+func (c *Controller) deleteManagedSecret(obj interface{}) {
+	sec, ok := obj.(*corev1.Secret)
+	if ok {
+		c.enqueueSecretClaims(sec)
+		return
+	}
+
+	tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+	if !ok {
+		c.logger.Errorf("Couldn't get object from tombstone %#v", obj)
+		return
+	}
+	sec, ok = tombstone.Obj.(*corev1.Secret)
+	if !ok {
+		c.logger.Errorf("Tombstone contained object that is not a Secret %#v", obj)
+		return
+	}
+	c.enqueueSecretClaims(sec)
+}
+
+// enqueueSecretClaims adds vault secret claims managing the secret to the
+// queue: the controller owner and the claims contributing to the shared
+// secret. The owner might be a cluster vault secret claim as well. A change of
+// a secret not managed by claims might resolve a conflict, so the conflicting
+// claims of the namespace are added instead.
+func (c *Controller) enqueueSecretClaims(sec *corev1.Secret) {
+	managed := false
+
+	if ref := metav1.GetControllerOf(sec); ref != nil && ref.Kind == v1alpha1.SchemeKind {
+		c.logger.Debugf("Secret \"%s/%s\" of VaultSecretClaim %q has changed", sec.Namespace, sec.Name, ref.Name)
+		c.queue.Add(sec.Namespace + "/" + ref.Name)
+		managed = true
+	}
+
+	if ref := metav1.GetControllerOf(sec); ref != nil && ref.Kind == clusterClaimKind && c.clusterQueue != nil {
+		c.logger.Debugf("Secret \"%s/%s\" of ClusterVaultSecretClaim %q has changed", sec.Namespace, sec.Name, ref.Name)
+		c.clusterQueue.Add(ref.Name)
+		managed = true
+	}
+
+	for claim := range managedKeys(sec) {
+		c.logger.Debugf("Shared Secret \"%s/%s\" of VaultSecretClaim %q has changed", sec.Namespace, sec.Name, claim)
+		c.queue.Add(sec.Namespace + "/" + claim)
+		managed = true
+	}
+
+	if !managed {
+		c.enqueueConflictingClaims(sec)
+		c.enqueueConflictingClusterClaims(sec)
+	}
+}
+
+// enqueueConflictingClaims adds vault secret claims that might produce the
+// secret and are not ready due to a conflict to the queue.
+func (c *Controller) enqueueConflictingClaims(sec *corev1.Secret) {
+	keys := []string{sec.Namespace + "/"}
+	for _, name := range targetNames(sec.Name) {
+		keys = append(keys, sec.Namespace+"/"+name)
+	}
+
+	for _, key := range keys {
+		objs, err := c.vscIndexer.ByIndex(targetIndex, key)
+		if err != nil {
+			c.logger.Errorf("Couldn't get VaultSecretClaims of Secret %q: %v", key, err)
+			return
+		}
+
+		for _, obj := range objs {
+			vsc := obj.(*v1alpha1.VaultSecretClaim)
+			cond := getCondition(vsc.Status.Conditions, v1alpha1.ClaimReady)
+			if cond != nil && cond.Reason == reasonConflict {
+				c.enqueue(vsc)
+			}
+		}
+	}
+}
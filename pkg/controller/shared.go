@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+const (
+	// managedKeysAnnotationPrefix prefixes the name of vault secret claim
+	// contributing keys to a shared secret. The annotation value is a comma
+	// separated list of the keys.
+	managedKeysAnnotationPrefix = "managed-keys.dweller.io/"
+
+	// contributorIndex is the name of secret informer index by key of vault
+	// secret claims contributing to the secret.
+	contributorIndex = "contributor"
+)
+
+// indexByContributor indexes shared secrets by keys of vault secret claims
+// contributing to them.
+func indexByContributor(obj interface{}) ([]string, error) {
+	meta, err := apimeta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for claim := range managedKeys(meta) {
+		keys = append(keys, meta.GetNamespace()+"/"+claim)
+	}
+	return keys, nil
+}
+
+// managedKeys returns keys of the shared secret by names of the vault secret
+// claims contributing them.
+func managedKeys(meta metav1.Object) map[string]map[string]bool {
+	contributions := make(map[string]map[string]bool)
+	for name, value := range meta.GetAnnotations() {
+		if !strings.HasPrefix(name, managedKeysAnnotationPrefix) {
+			continue
+		}
+
+		keys := make(map[string]bool)
+		for _, key := range strings.Split(value, ",") {
+			if key != "" {
+				keys[key] = true
+			}
+		}
+		contributions[strings.TrimPrefix(name, managedKeysAnnotationPrefix)] = keys
+	}
+	return contributions
+}
+
+// formatKeys formats keys as a value of managed keys annotation.
+func formatKeys(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// sharedOwnerRef returns non-controller owner reference to vault secret claim,
+// so the shared secret is garbage collected only when all the contributing
+// claims are deleted.
+func sharedOwnerRef(vsc *v1alpha1.VaultSecretClaim) metav1.OwnerReference {
+	ref := metav1.NewControllerRef(vsc, v1alpha1.SchemeGroupVersionKind)
+	ref.Controller = nil
+	ref.BlockOwnerDeletion = nil
+	return *ref
+}
+
+// isClaimRef reports whether the owner reference points at vault secret claim
+// with the given name.
+func isClaimRef(ref metav1.OwnerReference, claim string) bool {
+	return ref.Kind == v1alpha1.SchemeKind && ref.Name == claim
+}
+
+// syncSharedSecret merges keys of the assembled secret into the shared secret
+// keeping keys contributed by other vault secret claims. Conflict is reported
+// if the secret is controlled by someone else, is not shared or some keys are
+// contributed by other claims as well.
+func (c *Controller) syncSharedSecret(vsc *v1alpha1.VaultSecretClaim, sec *corev1.Secret) error {
+	annotation := managedKeysAnnotationPrefix + vsc.Name
+
+	c.logger.Debugf("Looking for shared Secret \"%s/%s\"", sec.Namespace, sec.Name)
+	current, err := c.secretLister.Secrets(sec.Namespace).Get(sec.Name)
+	if apierrors.IsNotFound(err) {
+		annotations := make(map[string]string, len(sec.Annotations)+1)
+		for k, v := range sec.Annotations {
+			annotations[k] = v
+		}
+		annotations[annotation] = formatKeys(sec.StringData)
+		sec.Annotations = annotations
+		sec.OwnerReferences = []metav1.OwnerReference{sharedOwnerRef(vsc)}
+
+		if err := c.createSecret(sec); err != nil {
+			return err
+		}
+		c.logger.Infof("Shared Secret \"%s/%s\" has been created", sec.Namespace, sec.Name)
+		c.recorder.Eventf(vsc, corev1.EventTypeNormal, eventSecretCreated, "Shared secret %q has been created", sec.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	contributions := managedKeys(current)
+
+	// Deep-copy otherwise we are mutating our cache.
+	updated := current.DeepCopy()
+
+	if metav1.IsControlledBy(current, vsc) {
+		// The secret was produced by the claim before it became shared, so
+		// all the keys are contributed by the claim.
+		keys := make(map[string]bool, len(current.Data))
+		for key := range current.Data {
+			keys[key] = true
+		}
+		contributions[vsc.Name] = keys
+	} else if metav1.GetControllerOf(current) != nil || len(contributions) == 0 {
+		return c.reportConflict(vsc, "secret", current)
+	}
+
+	for claim, keys := range contributions {
+		if claim == vsc.Name {
+			continue
+		}
+
+		if _, err := c.vscLister.VaultSecretClaims(current.Namespace).Get(claim); apierrors.IsNotFound(err) {
+			// The claim is gone, drop its contribution.
+			releaseContribution(updated, claim, keys)
+			continue
+		}
+
+		for key := range sec.StringData {
+			if keys[key] {
+				err := fmt.Errorf("Conflict: key %q of shared secret \"%s/%s\" is contributed by VaultSecretClaim %q as well. This must be resolved manually.", key, current.Namespace, current.Name, claim)
+				c.recordConflict(vsc, err)
+				return secret.NewError(secret.ClassConflict, err)
+			}
+		}
+	}
+
+	// Replace the keys previously contributed by the claim.
+	refs := updated.OwnerReferences
+	releaseContribution(updated, vsc.Name, contributions[vsc.Name])
+	for key, value := range sec.StringData {
+		updated.Data[key] = []byte(value)
+	}
+
+	if updated.Labels == nil {
+		updated.Labels = make(map[string]string)
+	}
+	for k, v := range sec.Labels {
+		updated.Labels[k] = v
+	}
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	for k, v := range sec.Annotations {
+		updated.Annotations[k] = v
+	}
+	updated.Annotations[annotation] = formatKeys(sec.StringData)
+	// Keep the owner reference in place, so that repeated syncs do not modify
+	// the secret.
+	updated.OwnerReferences = setClaimRef(refs, sharedOwnerRef(vsc))
+
+	if reflect.DeepEqual(updated, current) {
+		metrics.Add(metricSecretWritesSkipped, 1)
+		c.logger.Debugf("Shared Secret \"%s/%s\" is up to date", updated.Namespace, updated.Name)
+		return nil
+	}
+
+	_, err = c.client.CoreV1().Secrets(updated.Namespace).Update(updated)
+	if err != nil {
+		return fmt.Errorf("update kubernetes secret: %v", err)
+	}
+	metrics.Add(metricSecretWrites, 1)
+	c.logger.Infof("Shared Secret \"%s/%s\" has been updated", updated.Namespace, updated.Name)
+	hash := contentHash(sec)
+	c.event(vsc, corev1.EventTypeNormal, eventSecretUpdated, sec.Name, "Shared secret %q has been updated to content %s", sec.Name, hash)
+	c.rolloutDependents(vsc, sec.Name, hash)
+
+	return nil
+}
+
+// releaseContribution removes keys contributed by the vault secret claim to the
+// shared secret as well as the claim managed keys annotation and the owner
+// reference.
+func releaseContribution(sec *corev1.Secret, claim string, keys map[string]bool) {
+	if sec.Data == nil {
+		sec.Data = make(map[string][]byte)
+	}
+	for key := range keys {
+		delete(sec.Data, key)
+	}
+	delete(sec.Annotations, managedKeysAnnotationPrefix+claim)
+	sec.OwnerReferences = removeClaimRefs(sec.OwnerReferences, claim)
+}
+
+// removeClaimRefs returns owner references without the ones pointing at vault
+// secret claim with the given name.
+func removeClaimRefs(refs []metav1.OwnerReference, claim string) []metav1.OwnerReference {
+	var result []metav1.OwnerReference
+	for _, ref := range refs {
+		if !isClaimRef(ref, claim) {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+// setClaimRef sets the owner reference to vault secret claim in place of the
+// existing ones, if any, so the order of the references is stable.
+func setClaimRef(refs []metav1.OwnerReference, ref metav1.OwnerReference) []metav1.OwnerReference {
+	var (
+		result []metav1.OwnerReference
+		set    bool
+	)
+	for _, r := range refs {
+		if !isClaimRef(r, ref.Name) {
+			result = append(result, r)
+			continue
+		}
+		if !set {
+			result = append(result, ref)
+			set = true
+		}
+	}
+	if !set {
+		result = append(result, ref)
+	}
+	return result
+}
+
+// releaseSharedSecrets removes keys contributed by the vault secret claim from
+// shared secrets except for the kept ones. Shared secrets left without
+// contributions are deleted.
+func (c *Controller) releaseSharedSecrets(namespace, claim string, keep map[string]bool) error {
+	objs, err := c.secretIndexer.ByIndex(contributorIndex, namespace+"/"+claim)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		sec := obj.(*corev1.Secret)
+		if keep[sec.Name] {
+			continue
+		}
+
+		contributions := managedKeys(sec)
+		if len(contributions) == 1 {
+			err := c.client.CoreV1().Secrets(sec.Namespace).Delete(sec.Name, &metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("delete kubernetes secret: %v", err)
+			}
+			c.logger.Infof("Shared Secret \"%s/%s\" has no contributions left and has been deleted", sec.Namespace, sec.Name)
+			continue
+		}
+
+		// Deep-copy otherwise we are mutating our cache.
+		updated := sec.DeepCopy()
+		releaseContribution(updated, claim, contributions[claim])
+
+		_, err := c.client.CoreV1().Secrets(updated.Namespace).Update(updated)
+		if err != nil {
+			return fmt.Errorf("update kubernetes secret: %v", err)
+		}
+		c.logger.Infof("Keys of VaultSecretClaim \"%s/%s\" have been removed from shared Secret \"%s/%s\"", namespace, claim, sec.Namespace, sec.Name)
+	}
+
+	return nil
+}
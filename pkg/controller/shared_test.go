@@ -0,0 +1,299 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	dwellerfake "github.com/fukt/dweller/pkg/client/clientset/versioned/fake"
+	dwellerlisters "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+func newClaim(name string) *v1alpha1.VaultSecretClaim {
+	return &v1alpha1.VaultSecretClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")}}
+}
+
+// newSharedSecret returns the shared secret with the data contributed by the
+// claims, the keys of every claim are comma separated.
+func newSharedSecret(data map[string]string, contributions map[string]string) *corev1.Secret {
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "shared",
+			Annotations: make(map[string]string),
+		},
+		Data: make(map[string][]byte),
+	}
+	for key, value := range data {
+		sec.Data[key] = []byte(value)
+	}
+	for claim, keys := range contributions {
+		sec.Annotations[managedKeysAnnotationPrefix+claim] = keys
+		sec.OwnerReferences = append(sec.OwnerReferences, sharedOwnerRef(newClaim(claim)))
+	}
+	return sec
+}
+
+// newSharedTestController returns the controller with the claims and the
+// secrets in its caches and in the fake clientsets.
+func newSharedTestController(claims []string, secrets ...*corev1.Secret) *Controller {
+	c := newTestController(nil)
+	c.recorder = record.NewFakeRecorder(100)
+
+	claimIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	var claimObjs []runtime.Object
+	for _, name := range claims {
+		vsc := newClaim(name)
+		claimIndexer.Add(vsc)
+		claimObjs = append(claimObjs, vsc)
+	}
+	c.vscLister = dwellerlisters.NewVaultSecretClaimLister(claimIndexer)
+	c.clientset = dwellerfake.NewSimpleClientset(claimObjs...)
+
+	secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{contributorIndex: indexByContributor})
+	var secretObjs []runtime.Object
+	for _, sec := range secrets {
+		secretIndexer.Add(sec)
+		secretObjs = append(secretObjs, sec)
+	}
+	c.secretLister = corelisters.NewSecretLister(secretIndexer)
+	c.secretIndexer = secretIndexer
+	c.client = fake.NewSimpleClientset(secretObjs...)
+
+	return c
+}
+
+func secretData(sec *corev1.Secret) map[string]string {
+	data := make(map[string]string, len(sec.Data))
+	for key, value := range sec.Data {
+		data[key] = string(value)
+	}
+	return data
+}
+
+func TestSyncSharedSecret(t *testing.T) {
+	ownedByPostgres := newSharedSecret(map[string]string{"PG_URL": "old", "PG_STALE": "stale"}, nil)
+	ownedByPostgres.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(newClaim("postgres"), v1alpha1.SchemeGroupVersionKind)}
+
+	tests := []struct {
+		name        string
+		claims      []string
+		current     *corev1.Secret
+		contributed map[string]string
+		conflict    bool
+		wantData    map[string]string
+		wantClaims  []string
+	}{
+		{
+			name:        "merge",
+			claims:      []string{"postgres", "redis"},
+			current:     newSharedSecret(map[string]string{"REDIS_URL": "redis"}, map[string]string{"redis": "REDIS_URL"}),
+			contributed: map[string]string{"PG_URL": "postgres"},
+			wantData:    map[string]string{"REDIS_URL": "redis", "PG_URL": "postgres"},
+			wantClaims:  []string{"postgres", "redis"},
+		},
+		{
+			name:        "replace own keys",
+			claims:      []string{"postgres", "redis"},
+			current:     newSharedSecret(map[string]string{"REDIS_URL": "redis", "PG_URL": "old", "PG_OLD": "old"}, map[string]string{"redis": "REDIS_URL", "postgres": "PG_OLD,PG_URL"}),
+			contributed: map[string]string{"PG_URL": "postgres"},
+			wantData:    map[string]string{"REDIS_URL": "redis", "PG_URL": "postgres"},
+			wantClaims:  []string{"postgres", "redis"},
+		},
+		{
+			name:        "key collision",
+			claims:      []string{"postgres", "redis"},
+			current:     newSharedSecret(map[string]string{"URL": "redis"}, map[string]string{"redis": "URL"}),
+			contributed: map[string]string{"URL": "postgres"},
+			conflict:    true,
+			wantData:    map[string]string{"URL": "redis"},
+			wantClaims:  []string{"redis"},
+		},
+		{
+			name:        "gone contributor is dropped",
+			claims:      []string{"postgres"},
+			current:     newSharedSecret(map[string]string{"URL": "redis"}, map[string]string{"redis": "URL"}),
+			contributed: map[string]string{"URL": "postgres"},
+			wantData:    map[string]string{"URL": "postgres"},
+			wantClaims:  []string{"postgres"},
+		},
+		{
+			name:        "previously owned secret is adopted",
+			claims:      []string{"postgres"},
+			current:     ownedByPostgres,
+			contributed: map[string]string{"PG_URL": "postgres"},
+			wantData:    map[string]string{"PG_URL": "postgres"},
+			wantClaims:  []string{"postgres"},
+		},
+		{
+			name:        "unshared secret",
+			claims:      []string{"postgres"},
+			current:     newSharedSecret(map[string]string{"URL": "manual"}, nil),
+			contributed: map[string]string{"PG_URL": "postgres"},
+			conflict:    true,
+			wantData:    map[string]string{"URL": "manual"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newSharedTestController(tt.claims, tt.current)
+			defer c.queue.ShutDown()
+			defer c.rollouts.ShutDown()
+
+			sec := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "shared"},
+				StringData: tt.contributed,
+			}
+			err := c.syncSharedSecret(newClaim("postgres"), sec)
+			if tt.conflict {
+				if secret.ClassOf(err) != secret.ClassConflict {
+					t.Fatalf("got error %v, want conflict", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.client.CoreV1().Secrets("default").Get("shared", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := secretData(got); !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("got data %v, want %v", data, tt.wantData)
+			}
+
+			contributions := managedKeys(got)
+			if len(contributions) != len(tt.wantClaims) {
+				t.Errorf("got contributions %v, want contributions of %v", contributions, tt.wantClaims)
+			}
+			for _, claim := range tt.wantClaims {
+				if _, ok := contributions[claim]; !ok {
+					t.Errorf("got contributions %v, want contribution of %q", contributions, claim)
+				}
+				if !tt.conflict && !hasClaimRef(got.OwnerReferences, claim) {
+					t.Errorf("got owner references %v, want reference to %q", got.OwnerReferences, claim)
+				}
+			}
+			if ref := metav1.GetControllerOf(got); ref != nil && !tt.conflict {
+				t.Errorf("shared secret is controlled by %s %q", ref.Kind, ref.Name)
+			}
+		})
+	}
+}
+
+func TestReleaseSharedSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *corev1.Secret
+		deleted  bool
+		wantData map[string]string
+	}{
+		{
+			name:     "other contributors are kept",
+			current:  newSharedSecret(map[string]string{"REDIS_URL": "redis", "PG_URL": "postgres"}, map[string]string{"redis": "REDIS_URL", "postgres": "PG_URL"}),
+			wantData: map[string]string{"REDIS_URL": "redis"},
+		},
+		{
+			name:    "last contributor deletes the secret",
+			current: newSharedSecret(map[string]string{"PG_URL": "postgres"}, map[string]string{"postgres": "PG_URL"}),
+			deleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newSharedTestController([]string{"redis"}, tt.current)
+			defer c.queue.ShutDown()
+			defer c.rollouts.ShutDown()
+
+			if err := c.releaseSharedSecrets("default", "postgres", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.client.CoreV1().Secrets("default").Get("shared", metav1.GetOptions{})
+			if tt.deleted {
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("got error %v, want the secret to be deleted", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := secretData(got); !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("got data %v, want %v", data, tt.wantData)
+			}
+			if _, ok := managedKeys(got)["postgres"]; ok || hasClaimRef(got.OwnerReferences, "postgres") {
+				t.Errorf("contribution of the released claim is left: %v, %v", got.Annotations, got.OwnerReferences)
+			}
+		})
+	}
+}
+
+func TestSyncReleasesDeletedClaimOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing bool
+		wantData map[string]string
+	}{
+		{
+			name:     "deleted claim is released",
+			wantData: map[string]string{"REDIS_URL": "redis"},
+		},
+		{
+			name:     "claim relabelled out of the selector is kept",
+			existing: true,
+			wantData: map[string]string{"REDIS_URL": "redis", "PG_URL": "postgres"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := newSharedSecret(map[string]string{"REDIS_URL": "redis", "PG_URL": "postgres"}, map[string]string{"redis": "REDIS_URL", "postgres": "PG_URL"})
+			c := newSharedTestController([]string{"redis"}, current)
+			defer c.queue.ShutDown()
+			defer c.rollouts.ShutDown()
+
+			// The claim is out of the cache either way, but a relabelled one
+			// still exists in the API.
+			if tt.existing {
+				c.clientset = dwellerfake.NewSimpleClientset(newClaim("redis"), newClaim("postgres"))
+			}
+
+			if err := c.syncVaultSecretClaim("default/postgres"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.client.CoreV1().Secrets("default").Get("shared", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data := secretData(got); !reflect.DeepEqual(data, tt.wantData) {
+				t.Errorf("got data %v, want %v", data, tt.wantData)
+			}
+			if _, ok := managedKeys(got)["postgres"]; ok != tt.existing {
+				t.Errorf("got contributions %v, want contribution of postgres = %v", managedKeys(got), tt.existing)
+			}
+		})
+	}
+}
+
+func hasClaimRef(refs []metav1.OwnerReference, claim string) bool {
+	for _, ref := range refs {
+		if isClaimRef(ref, claim) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// Reasons of vault secret claim conditions.
+const (
+	reasonSynced       = "Synced"
+	reasonSecretsReady = "SecretsReady"
+	reasonSyncFailed   = "SyncFailed"
+	reasonParked       = "Parked"
+	reasonConflict     = "Conflict"
+	reasonInvalidSpec  = "InvalidSpec"
+)
+
+// lastSyncTimeInterval is the interval of refreshing the last sync time of the
+// status that is otherwise unchanged, so that resyncs don't write the status
+// and trigger yet another watch event every time.
+const lastSyncTimeInterval = 10 * time.Minute
+
+// isStatusUpdate reports whether only the status of vault secret claim was
+// changed.
+func isStatusUpdate(old, new *v1alpha1.VaultSecretClaim) bool {
+	return reflect.DeepEqual(old.Spec, new.Spec) && !reflect.DeepEqual(old.Status, new.Status)
+}
+
+// getCondition returns the condition of the given type or nil if there is no
+// such condition.
+func getCondition(conditions []v1alpha1.VaultSecretClaimCondition, condType v1alpha1.VaultSecretClaimConditionType) *v1alpha1.VaultSecretClaimCondition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// lastSyncTime returns the last sync time of the successful sync. The previous
+// one is kept unless the rest of the status has changed or it's older than
+// lastSyncTimeInterval.
+func lastSyncTime(last *metav1.Time, changed bool) *metav1.Time {
+	if !changed && last != nil && time.Since(last.Time) < lastSyncTimeInterval {
+		return last
+	}
+	now := metav1.Now()
+	return &now
+}
+
+// setCondition sets the condition of the given type. Transition time is kept
+// as is unless the condition status changes.
+func setCondition(conditions *[]v1alpha1.VaultSecretClaimCondition, condType v1alpha1.VaultSecretClaimConditionType, condStatus corev1.ConditionStatus, reason, message string) {
+	cond := getCondition(*conditions, condType)
+	if cond == nil {
+		*conditions = append(*conditions, v1alpha1.VaultSecretClaimCondition{Type: condType})
+		cond = &(*conditions)[len(*conditions)-1]
+	}
+
+	if cond.Status != condStatus {
+		cond.LastTransitionTime = metav1.Now()
+	}
+	cond.Status = condStatus
+	cond.Reason = reason
+	cond.Message = message
+}
+
+// recordSyncError stores the error of the last sync of vault secret claim with
+// the given key in its status with the given reason, or with PolicyDenied if
+// vault path policies denied reading some of its items. Secrets produced by the
+// previous syncs are left in place, so the claim stays ready if it was.
+func (c *Controller) recordSyncError(key string, syncErr error, reason string) {
+	vsc := c.claimByKey(key)
+	if vsc == nil {
+		// Nowhere to record the error.
+		return
+	}
+
+	status := vsc.Status.DeepCopy()
+	status.ObservedGeneration = vsc.Generation
+	status.ItemErrors = nil
+	policyDenied := false
+	if itemErrs, ok := syncErr.(secret.ItemErrors); ok {
+		for _, itemErr := range itemErrs {
+			status.ItemErrors = append(status.ItemErrors, v1alpha1.DataItemError{Key: itemErr.Key, Message: itemErr.Err.Error()})
+
+			reason := eventVaultReadFailed
+			if _, denied := itemErr.Err.(*secret.PolicyDeniedError); denied {
+				reason = eventPolicyDenied
+				policyDenied = true
+			} else if itemErr.Class() == secret.ClassPermission {
+				reason = eventPermissionDenied
+			}
+			c.event(vsc, corev1.EventTypeWarning, reason, itemErr.Key, "%v", itemErr)
+		}
+	}
+	if policyDenied {
+		// Denied claims are told by the reason to be retried as soon as
+		// policies change.
+		reason = reasonPolicyDenied
+	}
+	setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionFalse, reason, syncErr.Error())
+	if getCondition(status.Conditions, v1alpha1.ClaimReady) == nil {
+		setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionFalse, reason, "Secrets have not been synced yet")
+	}
+
+	// Deep-copy otherwise we are mutating our cache.
+	if err := c.updateStatus(vsc.DeepCopy(), *status); err != nil {
+		c.logger.Errorf("%v", err)
+	}
+}
+
+// recordConflict reports the conflict that must be resolved manually and marks
+// vault secret claim as not ready.
+func (c *Controller) recordConflict(vsc *v1alpha1.VaultSecretClaim, conflict error) {
+	c.logger.Warnf("%v", conflict)
+	c.event(vsc, corev1.EventTypeWarning, eventConflict, "", "%v", conflict)
+
+	status := vsc.Status.DeepCopy()
+	status.ObservedGeneration = vsc.Generation
+	setCondition(&status.Conditions, v1alpha1.ClaimSynced, corev1.ConditionFalse, reasonConflict, conflict.Error())
+	setCondition(&status.Conditions, v1alpha1.ClaimReady, corev1.ConditionFalse, reasonConflict, conflict.Error())
+	if err := c.updateStatus(vsc, *status); err != nil {
+		c.logger.Errorf("%v", err)
+	}
+}
+
+// updateStatus stores the status of vault secret claim if it has changed since
+// the last sync.
+func (c *Controller) updateStatus(vsc *v1alpha1.VaultSecretClaim, status v1alpha1.VaultSecretClaimStatus) error {
+	if reflect.DeepEqual(vsc.Status, status) {
+		return nil
+	}
+
+	if !reflect.DeepEqual(vsc.Status.SkippedItems, status.SkippedItems) {
+		for _, item := range status.SkippedItems {
+			c.logger.Warnf("VaultSecretClaim \"%s/%s\" skipped key %q: %s", vsc.Namespace, vsc.Name, item.Key, item.Reason)
+		}
+	}
+
+	// Spec of the claim might be rendered from a template, but the status
+	// subresource ignores any changes except the status.
+	claim := vsc.DeepCopy()
+	claim.Status = status
+
+	updated, err := c.clientset.DwellerV1alpha1().VaultSecretClaims(vsc.Namespace).UpdateStatus(claim)
+	if err != nil {
+		return fmt.Errorf("update vault secret claim status: %v", err)
+	}
+
+	vsc.Status = status
+	// The status might be updated again during this sync.
+	vsc.ResourceVersion = updated.ResourceVersion
+	return nil
+}
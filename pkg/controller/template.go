@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
+	"github.com/fukt/dweller/pkg/secret"
+	"github.com/fukt/dweller/pkg/template"
+)
+
+// templateIndex is the name of vault secret claim informer index by name of the
+// referenced template.
+const templateIndex = "template"
+
+// indexByTemplate indexes vault secret claims by name of the referenced
+// template.
+func indexByTemplate(obj interface{}) ([]string, error) {
+	vsc, ok := obj.(*v1alpha1.VaultSecretClaim)
+	if !ok || vsc.Spec.TemplateRef == nil {
+		return nil, nil
+	}
+	return []string{vsc.Spec.TemplateRef.Name}, nil
+}
+
+// WithClaimTemplates makes the controller render secrets of vault secret
+// claims referencing templates. Templates are cluster-scoped, so it requires
+// permissions to list and watch them cluster-wide regardless of the scope.
+func WithClaimTemplates() Option {
+	return func(c *Controller) {
+		c.claimTemplates = true
+	}
+}
+
+// setUpClaimTemplates sets up the informer of vault secret claim templates.
+func (c *Controller) setUpClaimTemplates() {
+	informer := c.customFactory.Dweller().V1alpha1().VaultSecretClaimTemplates().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addVaultSecretClaimTemplate,
+		UpdateFunc: c.updateVaultSecretClaimTemplate,
+		DeleteFunc: c.deleteVaultSecretClaimTemplate,
+	})
+	c.templateLister = c.customFactory.Dweller().V1alpha1().VaultSecretClaimTemplates().Lister()
+}
+
+func (c *Controller) addVaultSecretClaimTemplate(obj interface{}) {
+	tmpl := obj.(*v1alpha1.VaultSecretClaimTemplate)
+	c.logger.Infof("Adding VaultSecretClaimTemplate %q", tmpl.Name)
+	c.enqueueTemplateClaims(tmpl.Name)
+}
+
+func (c *Controller) updateVaultSecretClaimTemplate(old, new interface{}) {
+	oldTmpl := old.(*v1alpha1.VaultSecretClaimTemplate)
+	newTmpl := new.(*v1alpha1.VaultSecretClaimTemplate)
+	if oldTmpl.ResourceVersion == newTmpl.ResourceVersion {
+		// Periodic resync, dependent claims are resynced on their own.
+		return
+	}
+	c.logger.Infof("Updating VaultSecretClaimTemplate %q", newTmpl.Name)
+	c.enqueueTemplateClaims(newTmpl.Name)
+}
+
+func (c *Controller) deleteVaultSecretClaimTemplate(obj interface{}) {
+	tmpl, ok := obj.(*v1alpha1.VaultSecretClaimTemplate)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			c.logger.Errorf("Couldn't get object from tombstone %#v", obj)
+			return
+		}
+		tmpl, ok = tombstone.Obj.(*v1alpha1.VaultSecretClaimTemplate)
+		if !ok {
+			c.logger.Errorf("Tombstone contained object that is not a VaultSecretClaimTemplate %#v", obj)
+			return
+		}
+	}
+	c.logger.Infof("Deleting VaultSecretClaimTemplate %q", tmpl.Name)
+	c.enqueueTemplateClaims(tmpl.Name)
+}
+
+// enqueueTemplateClaims adds all vault secret claims referencing the template
+// to the queue.
+func (c *Controller) enqueueTemplateClaims(name string) {
+	objs, err := c.vscIndexer.ByIndex(templateIndex, name)
+	if err != nil {
+		c.logger.Errorf("Couldn't get VaultSecretClaims of template %q: %v", name, err)
+		return
+	}
+
+	for _, obj := range objs {
+		c.enqueue(obj.(*v1alpha1.VaultSecretClaim))
+	}
+}
+
+// resolveTemplate renders the secret of vault secret claim from the referenced
+// template, if any. The claim is modified in place, so it must be a copy.
+func (c *Controller) resolveTemplate(vsc *v1alpha1.VaultSecretClaim) error {
+	ref := vsc.Spec.TemplateRef
+	if ref == nil {
+		return nil
+	}
+	if !c.claimTemplates {
+		// The claim is synced again once templates are enabled on restart.
+		return secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("vault secret claim templates are disabled"))
+	}
+
+	tmpl, err := c.templateLister.Get(ref.Name)
+	if apierrors.IsNotFound(err) {
+		// The claim is synced again once the template is created.
+		return secret.NewError(secret.ClassNotFound, fmt.Errorf("vault secret claim template %q is not found", ref.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("get vault secret claim template %q: %v", ref.Name, err)
+	}
+
+	sec, err := template.Render(tmpl, ref.Parameters)
+	if err != nil {
+		return secret.NewError(secret.ClassInvalidSpec, err)
+	}
+	secretPath := field.NewPath("spec", "secret")
+	errs := validation.ValidateSecretTemplate(&sec, secretPath)
+	if vsc.Spec.Immutable {
+		errs = append(errs, validation.ValidateImmutableSecretName(&sec, vsc.Name, secretPath)...)
+	}
+	if len(errs) > 0 {
+		return secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("template %q: %v", ref.Name, errs.ToAggregate()))
+	}
+
+	vsc.Spec.Secret = sec
+	return nil
+}
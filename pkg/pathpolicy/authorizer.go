@@ -0,0 +1,154 @@
+// Package pathpolicy enforces vault path policies, which restrict Vault paths
+// vault secret claims of a namespace may read.
+package pathpolicy
+
+import (
+	"errors"
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
+	"github.com/fukt/dweller/pkg/client/clientset/versioned"
+	dwellerinformers "github.com/fukt/dweller/pkg/client/informers/externalversions/dweller/v1alpha1"
+	dwellerlisters "github.com/fukt/dweller/pkg/client/listers/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// resync is resync period of policies and namespaces.
+const resync = time.Minute * 5
+
+// errNotSynced is returned until policies and namespaces are synced, so that
+// reads are retried rather than denied.
+var errNotSynced = secret.NewError(secret.ClassTransient, errors.New("vault path policies are not synced yet"))
+
+// Authorizer authorizes vault secret claims to read Vault paths by vault path
+// policies. Claims may read only the paths allowed by some policy applying to
+// their namespace. Claims without a namespace, i.e. cluster vault secret
+// claims, are not checked here: the controller authorizes them with every
+// namespace their secret is written to before reading Vault.
+type Authorizer struct {
+	policyInformer    cache.SharedIndexInformer
+	namespaceInformer cache.SharedIndexInformer
+
+	policies   dwellerlisters.VaultPathPolicyLister
+	namespaces corelisters.NamespaceLister
+}
+
+// NewAuthorizer returns the authorizer watching vault path policies and
+// namespaces. It denies nothing until Run is called and the watched objects
+// are synced.
+func NewAuthorizer(client kubernetes.Interface, clientset versioned.Interface) *Authorizer {
+	policyInformer := dwellerinformers.NewVaultPathPolicyInformer(clientset, resync, cache.Indexers{})
+	namespaceInformer := coreinformers.NewNamespaceInformer(client, resync, cache.Indexers{})
+	return &Authorizer{
+		policyInformer:    policyInformer,
+		namespaceInformer: namespaceInformer,
+		policies:          dwellerlisters.NewVaultPathPolicyLister(policyInformer.GetIndexer()),
+		namespaces:        corelisters.NewNamespaceLister(namespaceInformer.GetIndexer()),
+	}
+}
+
+// Run watches vault path policies and namespaces until stopCh is closed.
+func (a *Authorizer) Run(stopCh <-chan struct{}) {
+	go a.policyInformer.Run(stopCh)
+	a.namespaceInformer.Run(stopCh)
+}
+
+// PolicyInformer returns the informer of vault path policies, e.g. to retry
+// denied claims once the policies change.
+func (a *Authorizer) PolicyInformer() cache.SharedInformer {
+	return a.policyInformer
+}
+
+// Authorize returns PolicyDeniedError if no policy allows claims of the
+// namespace to read the path with the engine.
+func (a *Authorizer) Authorize(namespace, vaultPath string, engine v1alpha1.SecretEngine) error {
+	if namespace == metav1.NamespaceAll {
+		return nil
+	}
+	if !a.policyInformer.HasSynced() || !a.namespaceInformer.HasSynced() {
+		return errNotSynced
+	}
+
+	ns, err := a.namespaces.Get(namespace)
+	switch {
+	case apierrors.IsNotFound(err):
+		// Policies selecting namespaces by their labels don't apply.
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	case err != nil:
+		return secret.NewError(secret.ClassTransient, err)
+	}
+
+	policies, err := a.policies.List(labels.Everything())
+	if err != nil {
+		return secret.NewError(secret.ClassTransient, err)
+	}
+	for _, p := range policies {
+		if appliesTo(&p.Spec, ns) && allows(p.Spec.Rules, vaultPath, engine) {
+			return nil
+		}
+	}
+	return &secret.PolicyDeniedError{Namespace: namespace, Path: vaultPath, Engine: engine}
+}
+
+// appliesTo reports whether the policy applies to the namespace. Policies with
+// malformed selectors apply to the listed namespaces only.
+func appliesTo(spec *v1alpha1.VaultPathPolicySpec, ns *corev1.Namespace) bool {
+	for _, name := range spec.Namespaces {
+		if name == ns.Name {
+			return true
+		}
+	}
+	if spec.NamespaceSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector)
+	return err == nil && selector.Matches(labels.Set(ns.Labels))
+}
+
+// allows reports whether any of the rules allows reading the path with the
+// engine. Malformed globs match nothing. Stars match "..", so paths with such
+// segments are never allowed.
+func allows(rules []v1alpha1.PathRule, vaultPath string, engine v1alpha1.SecretEngine) bool {
+	if len(validation.IsVaultPath(vaultPath)) > 0 {
+		return false
+	}
+	if engine == "" {
+		engine = v1alpha1.EngineKV
+	}
+	for _, rule := range rules {
+		if !allowsEngine(rule.Engines, engine) {
+			continue
+		}
+		for _, glob := range rule.Paths {
+			if ok, err := path.Match(glob, vaultPath); err == nil && ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowsEngine reports whether the engine is listed, all the engines are
+// allowed if none is.
+func allowsEngine(engines []v1alpha1.SecretEngine, engine v1alpha1.SecretEngine) bool {
+	if len(engines) == 0 {
+		return true
+	}
+	for _, e := range engines {
+		if e == engine {
+			return true
+		}
+	}
+	return false
+}
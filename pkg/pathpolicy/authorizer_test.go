@@ -0,0 +1,65 @@
+package pathpolicy
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+func TestAllows(t *testing.T) {
+	rules := []v1alpha1.PathRule{
+		{Paths: []string{"secret/team-a/*"}},
+		{Paths: []string{"secret/data/team-a/*"}, Engines: []v1alpha1.SecretEngine{v1alpha1.EngineKVv2}},
+		{Paths: []string{"secret/[a-"}},
+	}
+
+	tests := []struct {
+		path   string
+		engine v1alpha1.SecretEngine
+		want   bool
+	}{
+		{"secret/team-a/postgres", "", true},
+		{"secret/team-a/postgres", v1alpha1.EngineKVv2, true},
+		{"secret/team-a/postgres/replica", "", false},
+		{"secret/team-b/postgres", "", false},
+		{"secret/data/team-a/postgres", v1alpha1.EngineKVv2, true},
+		{"secret/data/team-a/postgres", v1alpha1.EngineKV, false},
+		{"secret/a", "", false},
+		{"secret/team-a/..", "", false},
+		{"secret/team-a/.", "", false},
+	}
+	for _, tt := range tests {
+		if got := allows(rules, tt.path, tt.engine); got != tt.want {
+			t.Errorf("allows(%q, %q) = %v, want %v", tt.path, tt.engine, got, tt.want)
+		}
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	spec := &v1alpha1.VaultPathPolicySpec{
+		Namespaces: []string{"team-a"},
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"team": "b"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   bool
+	}{
+		{"team-a", nil, true},
+		{"team-b", map[string]string{"team": "b"}, true},
+		{"team-c", map[string]string{"team": "c"}, false},
+		{"team-d", nil, false},
+	}
+	for _, tt := range tests {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tt.name, Labels: tt.labels}}
+		if got := appliesTo(spec, ns); got != tt.want {
+			t.Errorf("appliesTo(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
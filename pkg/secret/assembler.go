@@ -8,6 +8,16 @@ import (
 
 // Assembler can assemble kubernetes secret based on VaultSecretClaim.
 type Assembler interface {
-	// Assemble assembles kubernetes secret based on VaultSecretClaim.
-	Assemble(vsc *v1alpha1.VaultSecretClaim) (corev1.Secret, error)
+	// Assemble assembles kubernetes secret from the secret template of
+	// VaultSecretClaim. Data items that were not fetched from the secret
+	// provider are returned along with the secret.
+	Assemble(vsc *v1alpha1.VaultSecretClaim, tmpl *v1alpha1.SecretTemplate) (corev1.Secret, []v1alpha1.SkippedItem, error)
+}
+
+// PathAuthorizer authorizes vault secret claims to read paths of the secret
+// provider. Assemblers ask it before reading anything.
+type PathAuthorizer interface {
+	// Authorize returns PolicyDeniedError if claims of the namespace are not
+	// allowed to read the path with the engine.
+	Authorize(namespace, path string, engine v1alpha1.SecretEngine) error
 }
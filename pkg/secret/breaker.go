@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// ErrUnavailable is returned when the secret provider is not available.
+var ErrUnavailable = errors.New("secret provider is unavailable")
+
+// Health reports availability of the secret provider.
+type Health interface {
+	// Healthy reports whether the secret provider is available.
+	Healthy() bool
+}
+
+// CircuitBreaker is an assembler that fails fast without reaching the secret
+// provider while it's not healthy.
+type CircuitBreaker struct {
+	asm    Assembler
+	health Health
+}
+
+// NewCircuitBreaker returns the circuit breaker around the assembler.
+func NewCircuitBreaker(asm Assembler, health Health) *CircuitBreaker {
+	return &CircuitBreaker{asm: asm, health: health}
+}
+
+// Assemble assembles the secret with the underlying assembler if the secret
+// provider is healthy, ErrUnavailable is returned otherwise.
+func (cb *CircuitBreaker) Assemble(vsc *v1alpha1.VaultSecretClaim, tmpl *v1alpha1.SecretTemplate) (corev1.Secret, []v1alpha1.SkippedItem, error) {
+	if !cb.health.Healthy() {
+		return corev1.Secret{}, nil, ErrUnavailable
+	}
+	return cb.asm.Assemble(vsc, tmpl)
+}
@@ -0,0 +1,160 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// ItemError is an error of fetching a single data item of the secret.
+type ItemError struct {
+	Key string
+	Err error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("data item %q: %v", e.Key, e.Err)
+}
+
+// ItemErrors is a list of data item errors that occurred while assembling the
+// secret. Assemblers return it to report all the failed items at once.
+type ItemErrors []*ItemError
+
+func (errs ItemErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// PermissionError is an error of the secret provider denying access to the
+// secret.
+type PermissionError struct {
+	Path string
+	Err  error
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("permission denied to %q: %v", e.Path, e.Err)
+}
+
+// Class returns ClassPermission.
+func (e *PermissionError) Class() ErrorClass {
+	return ClassPermission
+}
+
+// PolicyDeniedError is an error of vault path policies not allowing claims of
+// the namespace to read the path. Like permission errors, it goes away once
+// access is granted.
+type PolicyDeniedError struct {
+	Namespace string
+	Path      string
+	Engine    v1alpha1.SecretEngine
+}
+
+func (e *PolicyDeniedError) Error() string {
+	if e.Engine == "" {
+		return fmt.Sprintf("vault path policies don't allow namespace %q to read %q", e.Namespace, e.Path)
+	}
+	return fmt.Sprintf("vault path policies don't allow namespace %q to read %q with engine %s", e.Namespace, e.Path, e.Engine)
+}
+
+// Class returns ClassPermission.
+func (e *PolicyDeniedError) Class() ErrorClass {
+	return ClassPermission
+}
+
+// NotFoundError is an error of a required secret field missing in the secret
+// provider.
+type NotFoundError struct {
+	Path  string
+	Field string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("field %q is not found at %q", e.Field, e.Path)
+}
+
+// Class returns ClassNotFound.
+func (e *NotFoundError) Class() ErrorClass {
+	return ClassNotFound
+}
+
+// ErrorClass tells what must happen for a failed sync to succeed, so that the
+// controller knows whether and when to retry it.
+type ErrorClass string
+
+const (
+	// ClassTransient errors might go away on their own, e.g. network errors.
+	ClassTransient ErrorClass = "Transient"
+	// ClassPermission errors go away once access to the secret is granted.
+	ClassPermission ErrorClass = "PermissionDenied"
+	// ClassNotFound errors go away once the missing secret is created.
+	ClassNotFound ErrorClass = "NotFound"
+	// ClassInvalidSpec errors go away only once the claim spec is fixed.
+	ClassInvalidSpec ErrorClass = "InvalidSpec"
+	// ClassConflict errors go away once the conflicting object is changed.
+	ClassConflict ErrorClass = "Conflict"
+)
+
+// classes lists error classes in order of preference when a single class must
+// be picked for several errors: the one the most likely to go away last.
+var classes = []ErrorClass{ClassInvalidSpec, ClassConflict, ClassPermission, ClassNotFound, ClassTransient}
+
+// classifier is implemented by errors of a known class.
+type classifier interface {
+	Class() ErrorClass
+}
+
+// ClassOf returns the class of the error. Errors of unknown class are
+// considered transient.
+func ClassOf(err error) ErrorClass {
+	if c, ok := err.(classifier); ok {
+		return c.Class()
+	}
+	return ClassTransient
+}
+
+// Error is an error of the given class.
+type Error struct {
+	class ErrorClass
+	err   error
+}
+
+// NewError returns the error of the given class.
+func NewError(class ErrorClass, err error) *Error {
+	return &Error{class: class, err: err}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Class returns the class of the error.
+func (e *Error) Class() ErrorClass {
+	return e.class
+}
+
+// Class returns the class of the item error.
+func (e *ItemError) Class() ErrorClass {
+	return ClassOf(e.Err)
+}
+
+// Class returns the class of the item errors the most likely to go away last,
+// since the secret can't be assembled until all the items are fetched: a
+// transient error of one item doesn't make retries worth it while another
+// item waits for the spec to be fixed.
+func (errs ItemErrors) Class() ErrorClass {
+	found := make(map[ErrorClass]bool, len(classes))
+	for _, err := range errs {
+		found[err.Class()] = true
+	}
+	for _, class := range classes {
+		if found[class] {
+			return class
+		}
+	}
+	return ClassTransient
+}
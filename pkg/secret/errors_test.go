@@ -0,0 +1,36 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestItemErrorsClass(t *testing.T) {
+	transient := errors.New("connection refused")
+	permission := NewError(ClassPermission, errors.New("permission denied"))
+	notFound := &NotFoundError{Path: "secret/postgres", Field: "password"}
+	invalid := NewError(ClassInvalidSpec, errors.New("invalid path"))
+
+	tests := []struct {
+		name string
+		errs []error
+		want ErrorClass
+	}{
+		{"transient", []error{transient}, ClassTransient},
+		{"transient and not found", []error{transient, notFound}, ClassNotFound},
+		{"transient and permission", []error{permission, transient}, ClassPermission},
+		{"not found and permission", []error{notFound, permission}, ClassPermission},
+		{"invalid spec among all", []error{transient, notFound, invalid, permission}, ClassInvalidSpec},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs ItemErrors
+			for _, err := range tt.errs {
+				errs = append(errs, &ItemError{Key: "KEY", Err: err})
+			}
+			if got := errs.Class(); got != tt.want {
+				t.Errorf("got class %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Hash returns a hash of the secret content, that is its type, labels,
+// annotations and data. Data and string data are hashed as a whole, so equal
+// values produce equal hashes regardless of the field they are set in.
+func Hash(sec *corev1.Secret) string {
+	data := make(map[string][]byte, len(sec.Data)+len(sec.StringData))
+	for k, v := range sec.Data {
+		data[k] = v
+	}
+	for k, v := range sec.StringData {
+		data[k] = []byte(v)
+	}
+
+	content := struct {
+		Type        corev1.SecretType
+		Labels      map[string]string
+		Annotations map[string]string
+		Data        map[string][]byte
+	}{
+		Type:        sec.Type,
+		Labels:      sec.Labels,
+		Annotations: sec.Annotations,
+		Data:        data,
+	}
+
+	// Maps are encoded with sorted keys, so the encoding is canonical.
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		// Can't happen: all the fields are plain maps of strings and bytes.
+		panic(err)
+	}
+
+	hasher := fnv.New32a()
+	hasher.Write(encoded)
+	return fmt.Sprintf("%08x", hasher.Sum32())
+}
@@ -0,0 +1,82 @@
+package template
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+// paramRef matches parameter references in the form of "$(name)".
+var paramRef = regexp.MustCompile(`\$\(([A-Za-z0-9_.-]+)\)`)
+
+// Render renders the secret template of vault secret claim template
+// substituting parameter references with the given values. It fails if a
+// required parameter is not set or an unknown parameter is set or referenced.
+func Render(tmpl *v1alpha1.VaultSecretClaimTemplate, values map[string]string) (v1alpha1.SecretTemplate, error) {
+	params := make(map[string]string, len(tmpl.Spec.Parameters))
+	for _, p := range tmpl.Spec.Parameters {
+		value, ok := values[p.Name]
+		switch {
+		case ok:
+			params[p.Name] = value
+		case p.Default != nil:
+			params[p.Name] = *p.Default
+		default:
+			return v1alpha1.SecretTemplate{}, fmt.Errorf("template %q: parameter %q is required", tmpl.Name, p.Name)
+		}
+	}
+
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			return v1alpha1.SecretTemplate{}, fmt.Errorf("template %q: unknown parameter %q", tmpl.Name, name)
+		}
+	}
+
+	r := renderer{params: params}
+
+	secret := *tmpl.Spec.Secret.DeepCopy()
+	secret.Name = r.render(secret.Name)
+	secret.Metadata.Labels = r.renderMap(secret.Metadata.Labels)
+	secret.Metadata.Annotations = r.renderMap(secret.Metadata.Annotations)
+	for i := range secret.Data {
+		item := &secret.Data[i]
+		item.Key = r.render(item.Key)
+		item.VaultPath = r.render(item.VaultPath)
+		item.VaultField = r.render(item.VaultField)
+		if item.Default != nil {
+			value := r.render(*item.Default)
+			item.Default = &value
+		}
+	}
+
+	if r.err != nil {
+		return v1alpha1.SecretTemplate{}, fmt.Errorf("template %q: %v", tmpl.Name, r.err)
+	}
+
+	return secret, nil
+}
+
+// renderer substitutes parameter references remembering the first error.
+type renderer struct {
+	params map[string]string
+	err    error
+}
+
+func (r *renderer) render(s string) string {
+	return paramRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := paramRef.FindStringSubmatch(ref)[1]
+		value, ok := r.params[name]
+		if !ok && r.err == nil {
+			r.err = fmt.Errorf("unknown parameter %q is referenced", name)
+		}
+		return value
+	})
+}
+
+func (r *renderer) renderMap(m map[string]string) map[string]string {
+	for k, v := range m {
+		m[k] = r.render(v)
+	}
+	return m
+}
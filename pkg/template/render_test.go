@@ -0,0 +1,102 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+)
+
+func newTemplate() *v1alpha1.VaultSecretClaimTemplate {
+	defaultUser := "app"
+	return &v1alpha1.VaultSecretClaimTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres"},
+		Spec: v1alpha1.VaultSecretClaimTemplateSpec{
+			Parameters: []v1alpha1.TemplateParameter{
+				{Name: "database"},
+				{Name: "user", Default: &defaultUser},
+			},
+			Secret: v1alpha1.SecretTemplate{
+				Name: "postgres-$(database)",
+				Metadata: metav1.ObjectMeta{
+					Labels: map[string]string{"database": "$(database)"},
+				},
+				Data: []v1alpha1.DataItem{
+					{Key: "USER", VaultPath: "secret/postgres/$(database)/$(user)", VaultField: "name"},
+					{Key: "PASSWORD", VaultPath: "secret/postgres/$(database)/$(user)", VaultField: "password"},
+				},
+			},
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := newTemplate()
+
+	got, err := Render(tmpl, map[string]string{"database": "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "postgres-orders" {
+		t.Errorf("got secret name %q, want postgres-orders", got.Name)
+	}
+	if got.Metadata.Labels["database"] != "orders" {
+		t.Errorf("got labels %v, want database=orders", got.Metadata.Labels)
+	}
+	for _, item := range got.Data {
+		if item.VaultPath != "secret/postgres/orders/app" {
+			t.Errorf("got Vault path %q of %s, want secret/postgres/orders/app", item.VaultPath, item.Key)
+		}
+	}
+
+	// The template is shared by claims, so it must be left as is.
+	if !reflect.DeepEqual(tmpl, newTemplate()) {
+		t.Errorf("template is modified by rendering: %+v", tmpl.Spec.Secret)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(tmpl *v1alpha1.VaultSecretClaimTemplate)
+		values map[string]string
+		err    string
+	}{
+		{
+			name:   "missing parameter",
+			values: map[string]string{"user": "admin"},
+			err:    `parameter "database" is required`,
+		},
+		{
+			name:   "extra parameter",
+			values: map[string]string{"database": "orders", "schema": "public"},
+			err:    `unknown parameter "schema"`,
+		},
+		{
+			name: "undeclared parameter referenced",
+			mutate: func(tmpl *v1alpha1.VaultSecretClaimTemplate) {
+				tmpl.Spec.Secret.Data[0].VaultField = "$(field)"
+			},
+			values: map[string]string{"database": "orders"},
+			err:    `unknown parameter "field" is referenced`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := newTemplate()
+			if tt.mutate != nil {
+				tt.mutate(tmpl)
+			}
+
+			_, err := Render(tmpl, tt.values)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
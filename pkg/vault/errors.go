@@ -0,0 +1,50 @@
+package vault
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// statusCodeRegexp matches the status code of Vault API response in the error
+// message. The API client does not expose the code other than in the message.
+var statusCodeRegexp = regexp.MustCompile(`Code: (\d+)\.`)
+
+// ResponseError is an error of reading Vault secret at the path. StatusCode is
+// the status code of Vault API response, it is zero if Vault was not reached.
+type ResponseError struct {
+	Path       string
+	StatusCode int
+	Err        error
+}
+
+func newResponseError(path string, err error) error {
+	respErr := &ResponseError{Path: path, Err: err}
+	if m := statusCodeRegexp.FindStringSubmatch(err.Error()); m != nil {
+		respErr.StatusCode, _ = strconv.Atoi(m[1])
+	}
+
+	if respErr.StatusCode == 403 {
+		// Reported apart from other errors, since access must be granted.
+		return &secret.PermissionError{Path: path, Err: err}
+	}
+	return respErr
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("read %q: %v", e.Path, e.Err)
+}
+
+// Class returns the class of the error by the status code: client errors are
+// caused by the claim, while server errors and network failures are
+// transient.
+func (e *ResponseError) Class() secret.ErrorClass {
+	switch e.StatusCode {
+	case 400, 404, 405:
+		return secret.ClassInvalidSpec
+	default:
+		return secret.ClassTransient
+	}
+}
@@ -0,0 +1,77 @@
+package vault
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/fukt/dweller/pkg/log"
+)
+
+// HealthMonitor periodically checks health of Vault. Vault is healthy if it's
+// reachable, initialized and unsealed.
+type HealthMonitor struct {
+	vault    *vault.Client
+	interval time.Duration
+	logger   log.Logger
+
+	mu      sync.RWMutex
+	healthy bool
+}
+
+// NewHealthMonitor returns new Vault health monitor checking Vault health at
+// the given interval. Vault is considered healthy until the first check.
+func NewHealthMonitor(vault *vault.Client, interval time.Duration, logger log.Logger) *HealthMonitor {
+	return &HealthMonitor{
+		vault:    vault,
+		interval: interval,
+		logger:   logger,
+		healthy:  true,
+	}
+}
+
+// Run checks Vault health until stopCh is closed.
+func (m *HealthMonitor) Run(stopCh <-chan struct{}) {
+	wait.Until(m.check, m.interval, stopCh)
+}
+
+// Healthy reports whether Vault was healthy at the last check.
+func (m *HealthMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.healthy
+}
+
+func (m *HealthMonitor) check() {
+	err := m.health()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case err != nil && m.healthy:
+		m.logger.Warnf("Vault is not healthy: %v", err)
+	case err == nil && !m.healthy:
+		m.logger.Infof("Vault is healthy again")
+	}
+	m.healthy = err == nil
+}
+
+// health returns the reason of Vault being not healthy, if any.
+func (m *HealthMonitor) health() error {
+	resp, err := m.vault.Sys().Health()
+	if err != nil {
+		return err
+	}
+	if !resp.Initialized {
+		return fmt.Errorf("not initialized")
+	}
+	if resp.Sealed {
+		return fmt.Errorf("sealed")
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package vault
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// kv2CacheTTL is how long cached KV v2 secrets are kept since they were last
+// read, so that secrets of deleted claims and changed paths don't stay in
+// memory forever.
+const kv2CacheTTL = time.Hour
+
+// kv2Secret is a version of KV v2 secret.
+type kv2Secret struct {
+	version string
+	data    map[string]interface{}
+
+	// lastRead is when the secret was last read from the cache.
+	lastRead time.Time
+}
+
+// kv2Cache caches the latest read versions of KV v2 secrets by their data
+// paths. Secrets not read within kv2CacheTTL are evicted.
+type kv2Cache struct {
+	mu        sync.Mutex
+	secrets   map[string]kv2Secret
+	lastSweep time.Time
+
+	// now returns the current time, it's replaced in tests.
+	now func() time.Time
+}
+
+func newKV2Cache() *kv2Cache {
+	return &kv2Cache{
+		secrets:   make(map[string]kv2Secret),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (kc *kv2Cache) get(path string) (kv2Secret, bool) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	now := kc.now()
+	kc.sweep(now)
+
+	sec, ok := kc.secrets[path]
+	if ok {
+		sec.lastRead = now
+		kc.secrets[path] = sec
+	}
+	return sec, ok
+}
+
+func (kc *kv2Cache) set(path string, sec kv2Secret) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	now := kc.now()
+	kc.sweep(now)
+
+	sec.lastRead = now
+	kc.secrets[path] = sec
+}
+
+func (kc *kv2Cache) delete(path string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	delete(kc.secrets, path)
+}
+
+// sweep evicts secrets not read within kv2CacheTTL. The cache is swept at most
+// once per kv2CacheTTL, so that reads don't walk all the secrets.
+func (kc *kv2Cache) sweep(now time.Time) {
+	if now.Sub(kc.lastSweep) < kv2CacheTTL {
+		return
+	}
+	kc.lastSweep = now
+
+	for path, sec := range kc.secrets {
+		if now.Sub(sec.lastRead) >= kv2CacheTTL {
+			delete(kc.secrets, path)
+		}
+	}
+}
+
+// readKV2 reads data of KV v2 secret at the data path. The metadata of the
+// secret is read first, and the secret itself is read only if its current
+// version differs from the cached one. It returns nil if there is no secret.
+func (asm *SecretAssembler) readKV2(path string) (map[string]interface{}, error) {
+	metadataPath, err := kv2MetadataPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := asm.read(metadataPath)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		asm.kv2.delete(path)
+		return nil, nil
+	}
+
+	version := fmt.Sprint(metadata.Data["current_version"])
+	if cached, ok := asm.kv2.get(path); ok && cached.version == version {
+		return cached.data, nil
+	}
+
+	vaultSecret, err := asm.read(path)
+	if err != nil {
+		return nil, err
+	}
+	if vaultSecret == nil {
+		asm.kv2.delete(path)
+		return nil, nil
+	}
+
+	// The secret might have been changed since the metadata was read, so the
+	// version of the read data is cached.
+	if m, ok := vaultSecret.Data["metadata"].(map[string]interface{}); ok {
+		version = fmt.Sprint(m["version"])
+	}
+
+	// Data is null for deleted and destroyed versions.
+	data, _ := vaultSecret.Data["data"].(map[string]interface{})
+	asm.kv2.set(path, kv2Secret{version: version, data: data})
+	return data, nil
+}
+
+// kv2MetadataPath returns the metadata path of KV v2 secret by its data path,
+// e.g. "secret/metadata/postgres" for "secret/data/postgres".
+func kv2MetadataPath(path string) (string, error) {
+	parts := strings.SplitN(path, "/data/", 2)
+	if len(parts) != 2 {
+		return "", secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("path %q is not a KV v2 data path", path))
+	}
+	return parts[0] + "/metadata/" + parts[1], nil
+}
@@ -0,0 +1,137 @@
+package vault
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	"github.com/fukt/dweller/pkg/secret"
+)
+
+// stubReader serves Vault secrets by their paths and counts reads.
+type stubReader struct {
+	secrets map[string]*vault.Secret
+	reads   map[string]int
+}
+
+func (r *stubReader) Read(path string) (*vault.Secret, error) {
+	r.reads[path]++
+	return r.secrets[path], nil
+}
+
+// setKV2 stores the version of KV v2 secret "secret/data/postgres".
+func (r *stubReader) setKV2(version string, data map[string]interface{}) {
+	r.secrets["secret/metadata/postgres"] = &vault.Secret{Data: map[string]interface{}{
+		"current_version": json.Number(version),
+	}}
+	r.secrets["secret/data/postgres"] = &vault.Secret{Data: map[string]interface{}{
+		"data":     data,
+		"metadata": map[string]interface{}{"version": json.Number(version)},
+	}}
+}
+
+func newTestAssembler() (*SecretAssembler, *stubReader) {
+	r := &stubReader{secrets: make(map[string]*vault.Secret), reads: make(map[string]int)}
+	return &SecretAssembler{logical: r, kv2: newKV2Cache()}, r
+}
+
+func TestKV2MetadataPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"secret/data/postgres", "secret/metadata/postgres"},
+		{"kv/team-a/data/postgres/replica", "kv/team-a/metadata/postgres/replica"},
+	}
+	for _, tt := range tests {
+		got, err := kv2MetadataPath(tt.path)
+		if err != nil {
+			t.Errorf("kv2MetadataPath(%q) failed: %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("kv2MetadataPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := kv2MetadataPath("secret/postgres"); secret.ClassOf(err) != secret.ClassInvalidSpec {
+		t.Errorf("got error %v for a path without /data/, want invalid spec", err)
+	}
+}
+
+func TestReadKV2SkipsUnchangedVersion(t *testing.T) {
+	asm, r := newTestAssembler()
+
+	r.setKV2("1", map[string]interface{}{"password": "first"})
+	for i := 0; i < 3; i++ {
+		data, err := asm.readKV2("secret/data/postgres")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data["password"] != "first" {
+			t.Fatalf("got data %v, want the first version", data)
+		}
+	}
+	if n := r.reads["secret/data/postgres"]; n != 1 {
+		t.Errorf("unchanged secret is read %d times, want once", n)
+	}
+
+	r.setKV2("2", map[string]interface{}{"password": "second"})
+	data, err := asm.readKV2("secret/data/postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"password": "second"}; !reflect.DeepEqual(data, want) {
+		t.Errorf("got data %v, want %v", data, want)
+	}
+	if n := r.reads["secret/data/postgres"]; n != 2 {
+		t.Errorf("changed secret is read %d times in total, want twice", n)
+	}
+}
+
+func TestReadKV2DeletedSecret(t *testing.T) {
+	asm, r := newTestAssembler()
+
+	r.setKV2("1", map[string]interface{}{"password": "first"})
+	if _, err := asm.readKV2("secret/data/postgres"); err != nil {
+		t.Fatal(err)
+	}
+
+	delete(r.secrets, "secret/metadata/postgres")
+	data, err := asm.readKV2("secret/data/postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != nil {
+		t.Errorf("got data %v of deleted secret, want none", data)
+	}
+	if _, ok := asm.kv2.get("secret/data/postgres"); ok {
+		t.Error("deleted secret is left in the cache")
+	}
+}
+
+func TestKV2CacheEvictsUnreadSecrets(t *testing.T) {
+	now := time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC)
+	kc := newKV2Cache()
+	kc.now = func() time.Time { return now }
+	kc.lastSweep = now
+
+	kc.set("secret/data/postgres", kv2Secret{version: "1"})
+	kc.set("secret/data/redis", kv2Secret{version: "1"})
+
+	now = now.Add(kv2CacheTTL / 2)
+	if _, ok := kc.get("secret/data/postgres"); !ok {
+		t.Fatal("secret is evicted before its time")
+	}
+
+	now = now.Add(kv2CacheTTL/2 + time.Minute)
+	if _, ok := kc.get("secret/data/postgres"); !ok {
+		t.Error("recently read secret is evicted")
+	}
+	if _, ok := kc.get("secret/data/redis"); ok {
+		t.Error("secret not read within the TTL is not evicted")
+	}
+}
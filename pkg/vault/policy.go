@@ -0,0 +1,31 @@
+package vault
+
+import (
+	vault "github.com/hashicorp/vault/api"
+)
+
+// PolicyChecker checks Vault paths against the policies of the dweller token.
+type PolicyChecker struct {
+	vault *vault.Client
+}
+
+// NewPolicyChecker returns new Vault policy checker.
+func NewPolicyChecker(vault *vault.Client) *PolicyChecker {
+	return &PolicyChecker{vault: vault}
+}
+
+// CanRead reports whether the token is allowed to read Vault secrets at the
+// path.
+func (pc *PolicyChecker) CanRead(path string) (bool, error) {
+	capabilities, err := pc.vault.Sys().CapabilitiesSelf(path)
+	if err != nil {
+		return false, newResponseError(path, err)
+	}
+
+	for _, capability := range capabilities {
+		if capability == "read" || capability == "root" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
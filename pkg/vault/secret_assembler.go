@@ -8,22 +8,42 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/secret"
 )
 
+// logicalReader reads Vault secrets, it's the logical backend of Vault client
+// outside of tests.
+type logicalReader interface {
+	Read(path string) (*vault.Secret, error)
+}
+
 // SecretAssembler assembles kubernetes secrets using Vault as a secret provider.
 type SecretAssembler struct {
-	vault *vault.Client
+	logical logicalReader
+
+	// kv2 caches secrets of KV v2 engine.
+	kv2 *kv2Cache
+
+	// authorizer authorizes claims to read Vault paths, the controller token
+	// might be allowed to read much more than any single claim should.
+	authorizer secret.PathAuthorizer
 }
 
-// NewSecretAssembler returns new Vault secret assembler.
-func NewSecretAssembler(vault *vault.Client) *SecretAssembler {
-	return &SecretAssembler{vault: vault}
+// NewSecretAssembler returns new Vault secret assembler. Vault paths are
+// authorized with the authorizer before reading them, unless it's nil.
+func NewSecretAssembler(vault *vault.Client, authorizer secret.PathAuthorizer) *SecretAssembler {
+	return &SecretAssembler{
+		logical:    vault.Logical(),
+		kv2:        newKV2Cache(),
+		authorizer: authorizer,
+	}
 }
 
-// Assemble assembles a kubernetes secret from the vault secret claim fetching
-// secret values from Vault.
-func (asm *SecretAssembler) Assemble(vsc *v1alpha1.VaultSecretClaim) (corev1.Secret, error) {
-	meta := asm.assembleMeta(vsc)
+// Assemble assembles a kubernetes secret from the secret template of vault
+// secret claim fetching secret values from Vault. Data items missing in Vault
+// are handled according to the claim failure policy and returned as skipped.
+func (asm *SecretAssembler) Assemble(vsc *v1alpha1.VaultSecretClaim, tmpl *v1alpha1.SecretTemplate) (corev1.Secret, []v1alpha1.SkippedItem, error) {
+	meta := asm.assembleMeta(vsc, tmpl)
 
 	secret := corev1.Secret{
 		ObjectMeta: meta,
@@ -31,24 +51,28 @@ func (asm *SecretAssembler) Assemble(vsc *v1alpha1.VaultSecretClaim) (corev1.Sec
 		StringData: make(map[string]string),
 	}
 
-	if err := asm.fetchVaultSecrets(vsc.Spec.Secret.Data, &secret); err != nil {
-		return secret, err
+	skipped, err := asm.fetchVaultSecrets(vsc.Namespace, tmpl.Data, vsc.Spec.FailurePolicy, &secret)
+	if err != nil {
+		return secret, skipped, err
 	}
 
-	return secret, nil
+	return secret, skipped, nil
 }
 
-func (asm *SecretAssembler) assembleMeta(vsc *v1alpha1.VaultSecretClaim) metav1.ObjectMeta {
+func (asm *SecretAssembler) assembleMeta(vsc *v1alpha1.VaultSecretClaim, tmpl *v1alpha1.SecretTemplate) metav1.ObjectMeta {
 	meta := metav1.ObjectMeta{}
 
-	// Force name to be equal to vsc name.
-	meta.Name = vsc.Name
+	// Name defaults to vsc name.
+	meta.Name = tmpl.Name
+	if meta.Name == "" {
+		meta.Name = vsc.Name
+	}
 	// Force the same namespace.
 	meta.Namespace = vsc.Namespace
 
-	// Only labels and annotations are copied from spec.secret.metadata.
-	meta.Labels = vsc.Spec.Secret.Metadata.Labels
-	meta.Annotations = vsc.Spec.Secret.Metadata.Annotations
+	// Only labels and annotations are copied from template metadata.
+	meta.Labels = tmpl.Metadata.Labels
+	meta.Annotations = tmpl.Metadata.Annotations
 
 	// Set ownership to benefit from garbage collection.
 	// See: https://kubernetes.io/docs/concepts/workloads/controllers/garbage-collection/
@@ -58,24 +82,102 @@ func (asm *SecretAssembler) assembleMeta(vsc *v1alpha1.VaultSecretClaim) metav1.
 	return meta
 }
 
-func (asm *SecretAssembler) fetchVaultSecrets(items []v1alpha1.DataItem, secret *corev1.Secret) error {
+func (asm *SecretAssembler) fetchVaultSecrets(namespace string, items []v1alpha1.DataItem, policy v1alpha1.FailurePolicy, sec *corev1.Secret) ([]v1alpha1.SkippedItem, error) {
+	// Unknown policies are treated as the most strict one.
+	failOnMissing := policy != v1alpha1.SkipMissing && policy != v1alpha1.KeepLastKnown
+
+	var (
+		skipped []v1alpha1.SkippedItem
+		errs    secret.ItemErrors
+	)
 	for _, item := range items {
-		vaultSecret, err := asm.vault.Logical().Read(item.VaultPath)
+		value, found, err := asm.readField(namespace, item)
 		if err != nil {
-			return err
+			errs = append(errs, &secret.ItemError{Key: item.Key, Err: err})
+			continue
 		}
 
-		var value string
-		fieldValue := vaultSecret.Data[item.VaultField]
-		switch fv := fieldValue.(type) {
-		case string:
-			value = fv
+		if found {
+			sec.StringData[item.Key] = value
+			continue
+		}
+
+		notFound := &secret.NotFoundError{Path: item.VaultPath, Field: item.VaultField}
+		reason := notFound.Error()
+		switch {
+		case item.Default != nil:
+			sec.StringData[item.Key] = *item.Default
+			skipped = append(skipped, v1alpha1.SkippedItem{Key: item.Key, Reason: reason + ", default value is used"})
+		case item.Optional || !failOnMissing:
+			skipped = append(skipped, v1alpha1.SkippedItem{Key: item.Key, Reason: reason})
 		default:
-			return fmt.Errorf("unknown type: %T", fieldValue)
+			errs = append(errs, &secret.ItemError{Key: item.Key, Err: notFound})
+		}
+	}
+
+	// All the failed items are reported at once.
+	if len(errs) > 0 {
+		return skipped, errs
+	}
+	return skipped, nil
+}
+
+// readField reads a single field of Vault secret the data item of a claim of
+// the namespace refers to. It reports whether the field was found.
+func (asm *SecretAssembler) readField(namespace string, item v1alpha1.DataItem) (string, bool, error) {
+	if asm.authorizer != nil {
+		if err := asm.authorizer.Authorize(namespace, item.VaultPath, item.Engine); err != nil {
+			return "", false, err
 		}
+	}
+
+	var (
+		data map[string]interface{}
+		err  error
+	)
+	switch item.Engine {
+	case v1alpha1.EngineKVv2:
+		data, err = asm.readKV2(item.VaultPath)
+	default:
+		data, err = asm.readData(item.VaultPath)
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	fieldValue, ok := data[item.VaultField]
+	if !ok || fieldValue == nil {
+		return "", false, nil
+	}
 
-		secret.StringData[item.Key] = value
+	switch fv := fieldValue.(type) {
+	case string:
+		return fv, true, nil
+	default:
+		return "", false, secret.NewError(secret.ClassInvalidSpec, fmt.Errorf("unknown type: %T", fieldValue))
+	}
+}
+
+// readData reads data of Vault secret at the path. It returns nil if there is
+// no secret.
+func (asm *SecretAssembler) readData(path string) (map[string]interface{}, error) {
+	vaultSecret, err := asm.read(path)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	// Vault responds with no secret at all if there is nothing at the path.
+	if vaultSecret == nil {
+		return nil, nil
+	}
+	return vaultSecret.Data, nil
+}
+
+// read reads Vault secret at the path.
+func (asm *SecretAssembler) read(path string) (*vault.Secret, error) {
+	vaultSecret, err := asm.logical.Read(path)
+	if err != nil {
+		return nil, newResponseError(path, err)
+	}
+	return vaultSecret, nil
 }
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fukt/dweller/pkg/log"
+)
+
+// The client we use does not ship types of admission.k8s.io/v1beta1, so
+// AdmissionReview is declared here with the same wire format.
+
+// AdmissionReview describes an admission review request and response.
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *AdmissionRequest  `json:"request,omitempty"`
+	Response *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the admission attributes of the request.
+type AdmissionRequest struct {
+	UID       types.UID                   `json:"uid"`
+	Kind      metav1.GroupVersionKind     `json:"kind"`
+	Resource  metav1.GroupVersionResource `json:"resource"`
+	Name      string                      `json:"name,omitempty"`
+	Namespace string                      `json:"namespace,omitempty"`
+	Operation string                      `json:"operation"`
+	Object    runtime.RawExtension        `json:"object,omitempty"`
+	OldObject runtime.RawExtension        `json:"oldObject,omitempty"`
+}
+
+// AdmissionResponse describes the admission response.
+type AdmissionResponse struct {
+	UID       types.UID      `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+}
+
+// jsonPatchType is the only patch type supported by admission webhooks.
+var jsonPatchType = "JSONPatch"
+
+// admitFunc admits the admission request.
+type admitFunc func(req *AdmissionRequest) *AdmissionResponse
+
+// allow returns the response allowing the request as is.
+func allow() *AdmissionResponse {
+	return &AdmissionResponse{Allowed: true}
+}
+
+// allowPatched returns the response allowing the request with the object
+// modified by the JSON patch.
+func allowPatched(patch []byte) *AdmissionResponse {
+	return &AdmissionResponse{Allowed: true, Patch: patch, PatchType: &jsonPatchType}
+}
+
+// deny returns the response denying the request with the error.
+func deny(err error) *AdmissionResponse {
+	return &AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		},
+	}
+}
+
+// admissionHandler returns the handler serving admission reviews with the
+// admit function.
+func admissionHandler(logger log.Logger, admit admitFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("read request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		var review AdmissionReview
+		if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+			http.Error(w, fmt.Sprintf("decode admission review: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		req := review.Request
+		logger.Debugf("Admitting %s of %s \"%s/%s\"", req.Operation, req.Kind.Kind, req.Namespace, req.Name)
+		resp := admit(req)
+		resp.UID = req.UID
+
+		encoded, err := json.Marshal(AdmissionReview{TypeMeta: review.TypeMeta, Response: resp})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("encode admission review: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(encoded)
+	}
+}
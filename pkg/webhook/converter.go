@@ -0,0 +1,212 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
+	"github.com/fukt/dweller/pkg/log"
+)
+
+// The client we use does not ship types of apiextensions.k8s.io/v1, so
+// ConversionReview is declared here with the same wire format.
+
+// ConversionReview describes a conversion request and response of custom
+// resources.
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *ConversionRequest  `json:"request,omitempty"`
+	Response *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest describes the objects to convert.
+type ConversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse describes the converted objects.
+type ConversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// Converter converts dweller resources between API versions, so that the
+// resources can be served in all the versions while stored in v1alpha1.
+type Converter struct {
+	logger log.Logger
+}
+
+// NewConverter returns the converter of dweller resources.
+func NewConverter(logger log.Logger) *Converter {
+	return &Converter{logger: logger}
+}
+
+// Register registers the conversion webhook at /convert.
+func (c *Converter) Register(s *Server) {
+	s.Handle("/convert", http.HandlerFunc(c.serveConversion))
+}
+
+func (c *Converter) serveConversion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var review ConversionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("decode conversion review: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	req := review.Request
+	c.logger.Debugf("Converting %d objects to %s", len(req.Objects), req.DesiredAPIVersion)
+	resp := c.convert(req)
+	if resp.Result.Status == metav1.StatusFailure {
+		c.logger.Warnf("Couldn't convert objects to %s: %s", req.DesiredAPIVersion, resp.Result.Message)
+	}
+
+	encoded, err := json.Marshal(ConversionReview{TypeMeta: review.TypeMeta, Response: resp})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("encode conversion review: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(encoded)
+}
+
+// convert converts all the objects of the request, the conversion fails if
+// any of them can't be converted.
+func (c *Converter) convert(req *ConversionRequest) *ConversionResponse {
+	resp := &ConversionResponse{UID: req.UID}
+	for _, obj := range req.Objects {
+		converted, err := convertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+// convertObject converts the encoded object to the API version. Metadata of
+// the object is kept as is, since the API server rejects conversions changing
+// it and the metadata type we use may lack some of the fields.
+func convertObject(raw []byte, version string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("decode object: %v", err)
+	}
+	if typeMeta.APIVersion == version {
+		return raw, nil
+	}
+
+	var (
+		converted interface{}
+		err       error
+	)
+	switch typeMeta.Kind {
+	case v1alpha1.SchemeKind:
+		converted, err = convertClaim(raw, typeMeta.APIVersion, version)
+	case "VaultSecretClaimTemplate":
+		converted, err = convertClaimTemplate(raw, typeMeta.APIVersion, version)
+	default:
+		err = fmt.Errorf("unsupported kind %q", typeMeta.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var original, fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &original); err != nil {
+		return nil, fmt.Errorf("decode object: %v", err)
+	}
+	encoded, err := json.Marshal(converted)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %v", typeMeta.Kind, err)
+	}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", typeMeta.Kind, err)
+	}
+	fields["metadata"] = original["metadata"]
+	return json.Marshal(fields)
+}
+
+// convertClaim converts vault secret claim through v1alpha1.
+func convertClaim(raw []byte, from, to string) (interface{}, error) {
+	var hub v1alpha1.VaultSecretClaim
+	switch from {
+	case v1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, &hub); err != nil {
+			return nil, fmt.Errorf("decode vault secret claim: %v", err)
+		}
+	case v1beta1.SchemeGroupVersion.String():
+		var vsc v1beta1.VaultSecretClaim
+		if err := json.Unmarshal(raw, &vsc); err != nil {
+			return nil, fmt.Errorf("decode vault secret claim: %v", err)
+		}
+		vsc.ConvertTo(&hub)
+	default:
+		return nil, fmt.Errorf("unsupported API version %q", from)
+	}
+
+	switch to {
+	case v1alpha1.SchemeGroupVersion.String():
+		hub.APIVersion = to
+		return &hub, nil
+	case v1beta1.SchemeGroupVersion.String():
+		var vsc v1beta1.VaultSecretClaim
+		vsc.ConvertFrom(&hub)
+		return &vsc, nil
+	}
+	return nil, fmt.Errorf("unsupported API version %q", to)
+}
+
+// convertClaimTemplate converts vault secret claim template through v1alpha1.
+func convertClaimTemplate(raw []byte, from, to string) (interface{}, error) {
+	var hub v1alpha1.VaultSecretClaimTemplate
+	switch from {
+	case v1alpha1.SchemeGroupVersion.String():
+		if err := json.Unmarshal(raw, &hub); err != nil {
+			return nil, fmt.Errorf("decode vault secret claim template: %v", err)
+		}
+	case v1beta1.SchemeGroupVersion.String():
+		var t v1beta1.VaultSecretClaimTemplate
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, fmt.Errorf("decode vault secret claim template: %v", err)
+		}
+		t.ConvertTo(&hub)
+	default:
+		return nil, fmt.Errorf("unsupported API version %q", from)
+	}
+
+	switch to {
+	case v1alpha1.SchemeGroupVersion.String():
+		hub.APIVersion = to
+		return &hub, nil
+	case v1beta1.SchemeGroupVersion.String():
+		var t v1beta1.VaultSecretClaimTemplate
+		t.ConvertFrom(&hub)
+		return &t, nil
+	}
+	return nil, fmt.Errorf("unsupported API version %q", to)
+}
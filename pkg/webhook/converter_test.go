@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/v1beta1"
+	"github.com/fukt/dweller/pkg/log"
+)
+
+const claimV1alpha1 = `{
+	"apiVersion": "dweller.io/v1alpha1",
+	"kind": "VaultSecretClaim",
+	"metadata": {
+		"name": "postgres",
+		"namespace": "default",
+		"managedFields": [{"manager": "kubectl"}]
+	},
+	"spec": {
+		"secret": {
+			"metadata": {"labels": {"app": "api"}},
+			"data": [
+				{"key": "PASSWORD", "vaultPath": "secret/data/postgres", "vaultField": "password", "engine": "kv-v2"},
+				{"key": "HOST", "vaultPath": "secret/postgres", "vaultField": "host", "default": "localhost", "configMap": true}
+			]
+		},
+		"failurePolicy": "SkipMissing",
+		"refreshInterval": "1h0m0s"
+	},
+	"status": {
+		"conditions": [{"type": "Ready", "status": "True", "lastTransitionTime": "2018-06-01T10:00:00Z"}],
+		"secretName": "postgres"
+	}
+}`
+
+func convertTo(t *testing.T, c *Converter, raw []byte, version string) []byte {
+	resp := c.convert(&ConversionRequest{
+		DesiredAPIVersion: version,
+		Objects:           []runtime.RawExtension{{Raw: raw}},
+	})
+	if resp.Result.Status != "Success" {
+		t.Fatalf("conversion to %s failed: %s", version, resp.Result.Message)
+	}
+	return resp.ConvertedObjects[0].Raw
+}
+
+func TestConvertClaim(t *testing.T) {
+	c := NewConverter(&log.Dummy{})
+
+	raw := convertTo(t, c, []byte(claimV1alpha1), v1beta1.SchemeGroupVersion.String())
+
+	var converted map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &converted); err != nil {
+		t.Fatal(err)
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(converted["metadata"], &meta); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := meta["managedFields"]; !ok {
+		t.Errorf("metadata is not kept as is: %s", converted["metadata"])
+	}
+
+	var vsc v1beta1.VaultSecretClaim
+	if err := json.Unmarshal(raw, &vsc); err != nil {
+		t.Fatal(err)
+	}
+	if vsc.APIVersion != "dweller.io/v1beta1" {
+		t.Errorf("got API version %q, want dweller.io/v1beta1", vsc.APIVersion)
+	}
+	want := &v1beta1.VaultSource{Path: "secret/data/postgres", Field: "password", Engine: v1beta1.EngineKVv2}
+	if got := vsc.Spec.Secret.Data[0].ValueFrom.Vault; !reflect.DeepEqual(got, want) {
+		t.Errorf("got Vault source %+v, want %+v", got, want)
+	}
+
+	// The conversion back must be lossless.
+	raw = convertTo(t, c, raw, v1alpha1.SchemeGroupVersion.String())
+
+	var got, orig v1alpha1.VaultSecretClaim
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(claimV1alpha1), &orig); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, orig) {
+		t.Errorf("got claim %+v after round trip, want %+v", got, orig)
+	}
+}
+
+func TestConvertUnsupportedVersion(t *testing.T) {
+	c := NewConverter(&log.Dummy{})
+
+	resp := c.convert(&ConversionRequest{
+		DesiredAPIVersion: "dweller.io/v2",
+		Objects:           []runtime.RawExtension{{Raw: []byte(claimV1alpha1)}},
+	})
+	if resp.Result.Status != "Failure" || len(resp.ConvertedObjects) != 0 {
+		t.Errorf("got %s with %d objects, want Failure without objects", resp.Result.Status, len(resp.ConvertedObjects))
+	}
+}
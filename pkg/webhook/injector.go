@@ -0,0 +1,396 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
+	"github.com/fukt/dweller/pkg/client/clientset/versioned"
+	"github.com/fukt/dweller/pkg/log"
+	"github.com/fukt/dweller/pkg/secret"
+	"github.com/fukt/dweller/pkg/template"
+)
+
+const (
+	// injectAnnotation is set on pods to the name of vault secret claim whose
+	// data is injected into the pod.
+	injectAnnotation = "dweller.io/inject"
+
+	// injectPathAnnotation is set on pods to the path the data is mounted at
+	// in every container. It defaults to defaultInjectPath.
+	injectPathAnnotation = "dweller.io/inject-path"
+
+	defaultInjectPath = "/var/run/dweller"
+
+	// injectVolumeName is the name of the in-memory volume holding the data.
+	injectVolumeName = "dweller-data"
+
+	// injectContainerName is the name of the init container populating the
+	// volume.
+	injectContainerName = "dweller-inject"
+
+	// tokenVolumeName is the name of the volume holding the service account
+	// token bound to the pod, which the init container authenticates with.
+	tokenVolumeName = "dweller-token"
+	tokenMountPath  = "/var/run/secrets/dweller.io/serviceaccount"
+
+	// Extra fields of users authenticated with tokens bound to pods.
+	podNameExtra = "authentication.kubernetes.io/pod-name"
+	podUIDExtra  = "authentication.kubernetes.io/pod-uid"
+
+	// serviceAccountPrefix prefixes user names of service accounts.
+	serviceAccountPrefix = "system:serviceaccount:"
+)
+
+// InjectorConfig configures the injection of vault secret claim data into
+// pods.
+type InjectorConfig struct {
+	// Image is the image of the init container, it must contain the
+	// dweller-inject binary.
+	Image string
+
+	// URL is the base URL of the webhook server reachable from pods.
+	URL string
+
+	// CABundle is the PEM encoded CA bundle the init container verifies the
+	// webhook server certificate with.
+	CABundle []byte
+}
+
+// Injector injects data of vault secret claims into pods instead of producing
+// secrets. Pods get an in-memory volume populated by an init container, which
+// fetches the data assembled by the injector.
+type Injector struct {
+	client    kubernetes.Interface
+	clientset versioned.Interface
+	asm       secret.Assembler
+	config    InjectorConfig
+	logger    log.Logger
+}
+
+// NewInjector returns the injector assembling data of claims with the
+// assembler.
+func NewInjector(client kubernetes.Interface, clientset versioned.Interface, asm secret.Assembler, config InjectorConfig, logger log.Logger) *Injector {
+	return &Injector{
+		client:    client,
+		clientset: clientset,
+		asm:       asm,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Register registers handlers of the injector at the server: the mutating
+// webhook of pods at /mutate-pods and the data of claims for init containers
+// at /inject/<namespace>/<claim>.
+func (inj *Injector) Register(s *Server) {
+	s.Handle("/mutate-pods", admissionHandler(inj.logger, inj.mutatePod))
+	s.Handle("/inject/", http.HandlerFunc(inj.serveData))
+}
+
+// patchOperation is an operation of JSON patch.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// mutatePod adds the in-memory volume and the init container populating it
+// to pods annotated with the name of vault secret claim. The volume is mounted
+// into every container of the pod.
+func (inj *Injector) mutatePod(req *AdmissionRequest) *AdmissionResponse {
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		return deny(fmt.Errorf("decode pod: %v", err))
+	}
+
+	claim := pod.Annotations[injectAnnotation]
+	if claim == "" {
+		return allow()
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == injectContainerName {
+			// Already injected.
+			return allow()
+		}
+	}
+
+	// Pods are often created with generated names, so the namespace is taken
+	// from the request.
+	namespace := req.Namespace
+	_, err := inj.claim(namespace, claim)
+	if apierrors.IsNotFound(err) {
+		return deny(fmt.Errorf("vault secret claim %q to inject is not found", claim))
+	}
+	if err != nil {
+		// Pods are not blocked while the claim can't be checked, the init
+		// container fails on its own if the claim turns out to be missing.
+		inj.logger.Warnf("Couldn't check VaultSecretClaim \"%s/%s\" to inject, injecting anyway: %v", namespace, claim, err)
+	}
+
+	path := pod.Annotations[injectPathAnnotation]
+	if path == "" {
+		path = defaultInjectPath
+	}
+
+	patch, err := json.Marshal(inj.podPatch(&pod, namespace, claim, path))
+	if err != nil {
+		return deny(fmt.Errorf("encode patch: %v", err))
+	}
+
+	inj.logger.Infof("Injecting VaultSecretClaim %q into Pod \"%s/%s\"", claim, namespace, pod.GenerateName+pod.Name)
+	return allowPatched(patch)
+}
+
+// podPatch returns JSON patch injecting data of the claim into the pod.
+func (inj *Injector) podPatch(pod *corev1.Pod, namespace, claim, path string) []patchOperation {
+	var patch []patchOperation
+
+	volume := corev1.Volume{
+		Name: injectVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory},
+		},
+	}
+	patch = append(patch, appendOperation("/spec/volumes", len(pod.Spec.Volumes) == 0, volume))
+
+	// The client we use does not ship service account token projections, so
+	// the volume is declared as is. The token is bound to the pod, so that
+	// data is served to pods annotated with the claim only.
+	tokenVolume := map[string]interface{}{
+		"name": tokenVolumeName,
+		"projected": map[string]interface{}{
+			"sources": []interface{}{
+				map[string]interface{}{
+					"serviceAccountToken": map[string]interface{}{
+						"path":              "token",
+						"expirationSeconds": 600,
+					},
+				},
+			},
+		},
+	}
+	patch = append(patch, patchOperation{Op: "add", Path: "/spec/volumes/-", Value: tokenVolume})
+
+	mount := corev1.VolumeMount{Name: injectVolumeName, MountPath: path}
+	tokenMount := corev1.VolumeMount{Name: tokenVolumeName, MountPath: tokenMountPath, ReadOnly: true}
+
+	initContainer := corev1.Container{
+		Name:    injectContainerName,
+		Image:   inj.config.Image,
+		Command: []string{"/dweller-inject"},
+		Env: []corev1.EnvVar{
+			{Name: "DWELLER_INJECT_URL", Value: fmt.Sprintf("%s/inject/%s/%s", strings.TrimSuffix(inj.config.URL, "/"), namespace, claim)},
+			{Name: "DWELLER_INJECT_PATH", Value: path},
+			{Name: "DWELLER_CA_BUNDLE", Value: string(inj.config.CABundle)},
+			{Name: "DWELLER_TOKEN_FILE", Value: tokenMountPath + "/token"},
+		},
+		VolumeMounts: []corev1.VolumeMount{mount, tokenMount},
+	}
+	// The data must be in place before any other init container starts.
+	if len(pod.Spec.InitContainers) == 0 {
+		patch = append(patch, patchOperation{Op: "add", Path: "/spec/initContainers", Value: []corev1.Container{initContainer}})
+	} else {
+		patch = append(patch, patchOperation{Op: "add", Path: "/spec/initContainers/0", Value: initContainer})
+	}
+
+	readOnly := mount
+	readOnly.ReadOnly = true
+	for i, c := range pod.Spec.InitContainers {
+		patch = append(patch, appendOperation(fmt.Sprintf("/spec/initContainers/%d/volumeMounts", i+1), len(c.VolumeMounts) == 0, readOnly))
+	}
+	for i, c := range pod.Spec.Containers {
+		patch = append(patch, appendOperation(fmt.Sprintf("/spec/containers/%d/volumeMounts", i), len(c.VolumeMounts) == 0, readOnly))
+	}
+
+	return patch
+}
+
+// appendOperation returns the operation appending the value to the array at
+// the path. Adding to a missing array fails, so the array is added as a whole
+// if it's empty.
+func appendOperation(path string, empty bool, value interface{}) patchOperation {
+	if empty {
+		return patchOperation{Op: "add", Path: path, Value: []interface{}{value}}
+	}
+	return patchOperation{Op: "add", Path: path + "/-", Value: value}
+}
+
+// serveData serves data of vault secret claim to the init container. The
+// container authenticates with the token of its service account, which must
+// belong to the namespace of the claim: pods of a namespace are allowed to
+// read data of claims of the same namespace, just like its secrets.
+func (inj *Injector) serveData(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/inject/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	namespace, claim := parts[0], parts[1]
+
+	if err := inj.authenticate(r, namespace, claim); err != nil {
+		inj.logger.Warnf("Denied data of VaultSecretClaim \"%s/%s\": %v", namespace, claim, err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	data, err := inj.assemble(namespace, claim)
+	if apierrors.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		inj.logger.Errorf("Couldn't assemble data of VaultSecretClaim \"%s/%s\": %v", namespace, claim, err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("encode data: %v", err), http.StatusInternalServerError)
+		return
+	}
+	inj.logger.Infof("Data of VaultSecretClaim \"%s/%s\" has been injected", namespace, claim)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(encoded)
+}
+
+// authenticate checks that the request is made with a token bound to a pod of
+// the namespace annotated with the claim. Any service account of the namespace
+// could read data of all of its claims otherwise, even without access to
+// secrets.
+func (inj *Injector) authenticate(r *http.Request, namespace, claim string) error {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return fmt.Errorf("no service account token")
+	}
+
+	review, err := inj.client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return fmt.Errorf("review token: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return fmt.Errorf("token is not authenticated: %s", review.Status.Error)
+	}
+
+	user := review.Status.User
+	if !strings.HasPrefix(user.Username, serviceAccountPrefix+namespace+":") {
+		return fmt.Errorf("user %q is not a service account of namespace %q", user.Username, namespace)
+	}
+
+	podName, podUID := extraValue(user.Extra, podNameExtra), extraValue(user.Extra, podUIDExtra)
+	if podName == "" {
+		return fmt.Errorf("token of user %q is not bound to a pod", user.Username)
+	}
+	pod, err := inj.client.CoreV1().Pods(namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get pod %q the token is bound to: %v", podName, err)
+	}
+	if string(pod.UID) != podUID {
+		return fmt.Errorf("pod %q the token is bound to is gone", podName)
+	}
+	if pod.Annotations[injectAnnotation] != claim {
+		return fmt.Errorf("pod %q is not annotated with vault secret claim %q", podName, claim)
+	}
+	return nil
+}
+
+// extraValue returns the first value of the extra field of the user.
+func extraValue(extra map[string]authenticationv1.ExtraValue, key string) string {
+	if len(extra[key]) == 0 {
+		return ""
+	}
+	return extra[key][0]
+}
+
+// claim returns vault secret claim of the namespace with the name or, if there
+// is none, the cluster vault secret claim with the name selecting the
+// namespace. Claims are read in v1alpha1, the stored version, which is served
+// whatever version they were written in.
+func (inj *Injector) claim(namespace, name string) (*v1alpha1.VaultSecretClaim, error) {
+	vsc, err := inj.clientset.DwellerV1alpha1().VaultSecretClaims(namespace).Get(name, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		return vsc, err
+	}
+	notFound := err
+
+	cvsc, err := inj.clientset.DwellerV1alpha1().ClusterVaultSecretClaims().Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get cluster vault secret claim %q: %v", name, err)
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&cvsc.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("cluster vault secret claim %q: %v", name, err)
+	}
+	ns, err := inj.client.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get namespace %q: %v", namespace, err)
+	}
+	if !selector.Matches(labels.Set(ns.Labels)) {
+		return nil, notFound
+	}
+
+	// Paths are authorized with policies of the namespace the data is
+	// injected into.
+	return &v1alpha1.VaultSecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: cvsc.Name, UID: cvsc.UID},
+		Spec: v1alpha1.VaultSecretClaimSpec{
+			Secret:        cvsc.Spec.Secret,
+			FailurePolicy: cvsc.Spec.FailurePolicy,
+		},
+	}, nil
+}
+
+// assemble assembles data of all the secret templates of vault secret claim.
+func (inj *Injector) assemble(namespace, name string) (map[string]string, error) {
+	vsc, err := inj.claim(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref := vsc.Spec.TemplateRef; ref != nil {
+		tmpl, err := inj.clientset.DwellerV1alpha1().VaultSecretClaimTemplates().Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get vault secret claim template %q: %v", ref.Name, err)
+		}
+		if vsc.Spec.Secret, err = template.Render(tmpl, ref.Parameters); err != nil {
+			return nil, err
+		}
+		if errs := validation.ValidateSecretTemplate(&vsc.Spec.Secret, field.NewPath("spec", "secret")); len(errs) > 0 {
+			return nil, fmt.Errorf("template %q: %v", ref.Name, errs.ToAggregate())
+		}
+	}
+
+	data := make(map[string]string)
+	for _, tmpl := range vsc.SecretTemplates() {
+		sec, _, err := inj.asm.Assemble(vsc, &tmpl)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range sec.StringData {
+			if errs := utilvalidation.IsConfigMapKey(key); len(errs) > 0 {
+				return nil, fmt.Errorf("key %q can't be injected: %s", key, strings.Join(errs, ", "))
+			}
+			data[key] = value
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/client/clientset/versioned/fake"
+	"github.com/fukt/dweller/pkg/log"
+)
+
+func admitPod(t *testing.T, inj *Injector, pod *corev1.Pod) *AdmissionResponse {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inj.mutatePod(&AdmissionRequest{Namespace: "default", Object: runtime.RawExtension{Raw: raw}})
+}
+
+func TestMutatePod(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&v1alpha1.VaultSecretClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "postgres"},
+	})
+	inj := NewInjector(nil, clientset, nil, InjectorConfig{Image: "fukt/dweller", URL: "https://dweller.default.svc/"}, &log.Dummy{})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "api-",
+			Annotations:  map[string]string{injectAnnotation: "postgres"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "api"},
+				{Name: "sidecar", VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/etc/config"}}},
+			},
+		},
+	}
+
+	resp := admitPod(t, inj, pod)
+	if !resp.Allowed {
+		t.Fatalf("pod is denied: %v", resp.Result.Message)
+	}
+
+	var patch []struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, op := range patch {
+		paths = append(paths, op.Path)
+	}
+	want := []string{
+		"/spec/volumes",
+		"/spec/volumes/-",
+		"/spec/initContainers",
+		"/spec/containers/0/volumeMounts",
+		"/spec/containers/1/volumeMounts/-",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("patched paths = %v, want %v", paths, want)
+	}
+
+	// Pods without the annotation are left alone.
+	delete(pod.Annotations, injectAnnotation)
+	if resp := admitPod(t, inj, pod); !resp.Allowed || resp.Patch != nil {
+		t.Errorf("pod without annotation is modified: %+v", resp)
+	}
+
+	// Pods of missing claims are denied.
+	pod.Annotations[injectAnnotation] = "redis"
+	if resp := admitPod(t, inj, pod); resp.Allowed {
+		t.Error("pod of missing claim is allowed")
+	}
+}
+
+func patchedPaths(t *testing.T, resp *AdmissionResponse) []string {
+	var patch []struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, op := range patch {
+		paths = append(paths, op.Path)
+	}
+	return paths
+}
+
+func TestPodPatchWithInitContainers(t *testing.T) {
+	inj := NewInjector(nil, nil, nil, InjectorConfig{Image: "fukt/dweller", URL: "https://dweller.default.svc"}, &log.Dummy{})
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{Name: "config"}},
+			InitContainers: []corev1.Container{
+				{Name: "migrate", VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/etc/config"}}},
+				{Name: "wait"},
+			},
+			Containers: []corev1.Container{{Name: "api"}},
+		},
+	}
+
+	var paths []string
+	for _, op := range inj.podPatch(pod, "default", "postgres", defaultInjectPath) {
+		paths = append(paths, op.Path)
+	}
+	// The injected init container goes first, so the existing ones are
+	// shifted by one.
+	want := []string{
+		"/spec/volumes/-",
+		"/spec/volumes/-",
+		"/spec/initContainers/0",
+		"/spec/initContainers/1/volumeMounts/-",
+		"/spec/initContainers/2/volumeMounts",
+		"/spec/containers/0/volumeMounts",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("patched paths = %v, want %v", paths, want)
+	}
+}
+
+func TestMutatePodClaimCheckFailure(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("get", "vaultsecretclaims", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("etcdserver: request timed out")
+	})
+	inj := NewInjector(nil, clientset, nil, InjectorConfig{Image: "fukt/dweller", URL: "https://dweller.default.svc"}, &log.Dummy{})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{injectAnnotation: "postgres"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api"}}},
+	}
+	resp := admitPod(t, inj, pod)
+	if !resp.Allowed {
+		t.Fatalf("pod is denied: %v", resp.Result.Message)
+	}
+	if len(patchedPaths(t, resp)) == 0 {
+		t.Error("pod is not injected")
+	}
+}
+
+func TestMutatePodClusterClaim(t *testing.T) {
+	client := kubefake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"registry-access": "true"}},
+	})
+	clientset := fake.NewSimpleClientset(
+		&v1alpha1.ClusterVaultSecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry"},
+			Spec: v1alpha1.ClusterVaultSecretClaimSpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"registry-access": "true"}},
+			},
+		},
+		&v1alpha1.ClusterVaultSecretClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "billing"},
+			Spec: v1alpha1.ClusterVaultSecretClaimSpec{
+				NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "billing"}},
+			},
+		},
+	)
+	inj := NewInjector(client, clientset, nil, InjectorConfig{Image: "fukt/dweller", URL: "https://dweller.default.svc"}, &log.Dummy{})
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{injectAnnotation: "registry"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api"}}},
+	}
+	if resp := admitPod(t, inj, pod); !resp.Allowed {
+		t.Errorf("pod of cluster claim selecting its namespace is denied: %v", resp.Result.Message)
+	}
+
+	pod.Annotations[injectAnnotation] = "billing"
+	if resp := admitPod(t, inj, pod); resp.Allowed {
+		t.Error("pod of cluster claim not selecting its namespace is allowed")
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "api-1",
+			UID:         "api-1-uid",
+			Annotations: map[string]string{injectAnnotation: "postgres"},
+		},
+	}
+	boundTo := func(name, uid string) map[string]authenticationv1.ExtraValue {
+		return map[string]authenticationv1.ExtraValue{podNameExtra: {name}, podUIDExtra: {uid}}
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		extra    map[string]authenticationv1.ExtraValue
+		claim    string
+		allowed  bool
+	}{
+		{
+			name:     "token bound to annotated pod",
+			username: "system:serviceaccount:default:api",
+			extra:    boundTo("api-1", "api-1-uid"),
+			claim:    "postgres",
+			allowed:  true,
+		},
+		{
+			name:     "token not bound to a pod",
+			username: "system:serviceaccount:default:api",
+			claim:    "postgres",
+		},
+		{
+			name:     "pod annotated with another claim",
+			username: "system:serviceaccount:default:api",
+			extra:    boundTo("api-1", "api-1-uid"),
+			claim:    "redis",
+		},
+		{
+			name:     "pod recreated with the same name",
+			username: "system:serviceaccount:default:api",
+			extra:    boundTo("api-1", "old-uid"),
+			claim:    "postgres",
+		},
+		{
+			name:     "service account of another namespace",
+			username: "system:serviceaccount:other:api",
+			extra:    boundTo("api-1", "api-1-uid"),
+			claim:    "postgres",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := kubefake.NewSimpleClientset(pod)
+			client.PrependReactor("create", "tokenreviews", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, &authenticationv1.TokenReview{Status: authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					User:          authenticationv1.UserInfo{Username: tt.username, Extra: tt.extra},
+				}}, nil
+			})
+			inj := NewInjector(client, nil, nil, InjectorConfig{}, &log.Dummy{})
+
+			r := httptest.NewRequest("GET", "/inject/default/"+tt.claim, nil)
+			r.Header.Set("Authorization", "Bearer token")
+			err := inj.authenticate(r, "default", tt.claim)
+			if allowed := err == nil; allowed != tt.allowed {
+				t.Errorf("allowed = %v (%v), want %v", allowed, err, tt.allowed)
+			}
+		})
+	}
+}
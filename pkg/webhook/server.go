@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"k8s.io/client-go/util/cert"
+
+	"github.com/fukt/dweller/pkg/log"
+)
+
+// Server serves admission webhooks over TLS.
+type Server struct {
+	server *http.Server
+	mux    *http.ServeMux
+	logger log.Logger
+}
+
+// NewServer returns the webhook server listening at the address with the PEM
+// encoded certificate and key.
+func NewServer(addr string, certPEM, keyPEM []byte, logger log.Logger) (*Server, error) {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("load webhook certificate: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	return &Server{
+		server: &http.Server{
+			Addr:      addr,
+			Handler:   mux,
+			TLSConfig: &tls.Config{Certificates: []tls.Certificate{certificate}},
+		},
+		mux:    mux,
+		logger: logger,
+	}, nil
+}
+
+// SelfSignedCertificate generates a certificate for the host signed by a new
+// CA for local testing. The returned certificate is followed by the CA one, so
+// it's the CA bundle as well.
+func SelfSignedCertificate(host string) (certPEM, keyPEM []byte, err error) {
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	}
+	return cert.GenerateSelfSignedCertKey(host, ips, nil)
+}
+
+// Handle registers the handler for the pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Run serves webhooks until stopCh is closed.
+func (s *Server) Run(stopCh <-chan struct{}) error {
+	go func() {
+		<-stopCh
+		s.server.Close()
+	}()
+
+	s.logger.Infof("Serving webhooks at %s", s.server.Addr)
+	// The certificate is already loaded to the TLS config.
+	err := s.server.ListenAndServeTLS("", "")
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/fukt/dweller/pkg/apis/dweller/v1alpha1"
+	"github.com/fukt/dweller/pkg/apis/dweller/validation"
+	"github.com/fukt/dweller/pkg/log"
+)
+
+// PathChecker checks whether Vault paths can be read by dweller.
+type PathChecker interface {
+	// CanRead reports whether the secrets at the path can be read.
+	CanRead(path string) (bool, error)
+}
+
+// Validator validates vault secret claims on admission, so that malformed
+// claims are rejected instead of failing on sync.
+type Validator struct {
+	paths  PathChecker
+	logger log.Logger
+}
+
+// NewValidator returns the validator of vault secret claims. Vault paths of
+// data items are checked with the path checker unless it's nil.
+func NewValidator(paths PathChecker, logger log.Logger) *Validator {
+	return &Validator{paths: paths, logger: logger}
+}
+
+// Register registers the validating webhooks of vault secret claims at
+// /validate-claims, of cluster vault secret claims at
+// /validate-cluster-claims and of vault path policies at
+// /validate-path-policies.
+func (v *Validator) Register(s *Server) {
+	s.Handle("/validate-claims", admissionHandler(v.logger, v.validateClaim))
+	s.Handle("/validate-cluster-claims", admissionHandler(v.logger, v.validateClusterClaim))
+	s.Handle("/validate-path-policies", admissionHandler(v.logger, v.validatePathPolicy))
+}
+
+func (v *Validator) validateClaim(req *AdmissionRequest) *AdmissionResponse {
+	var vsc v1alpha1.VaultSecretClaim
+	if err := json.Unmarshal(req.Object.Raw, &vsc); err != nil {
+		return deny(fmt.Errorf("decode vault secret claim: %v", err))
+	}
+
+	errs := validation.ValidateVaultSecretClaim(&vsc)
+	if v.paths != nil {
+		errs = append(errs, v.checkPaths(&vsc)...)
+	}
+	if len(errs) == 0 {
+		return allow()
+	}
+
+	v.logger.Infof("VaultSecretClaim \"%s/%s\" is rejected: %v", req.Namespace, vsc.Name, errs.ToAggregate())
+	status := apierrors.NewInvalid(v1alpha1.SchemeGroupVersionKind.GroupKind(), vsc.Name, errs).ErrStatus
+	return &AdmissionResponse{Allowed: false, Result: &status}
+}
+
+func (v *Validator) validateClusterClaim(req *AdmissionRequest) *AdmissionResponse {
+	var cvsc v1alpha1.ClusterVaultSecretClaim
+	if err := json.Unmarshal(req.Object.Raw, &cvsc); err != nil {
+		return deny(fmt.Errorf("decode cluster vault secret claim: %v", err))
+	}
+
+	errs := validation.ValidateClusterVaultSecretClaim(&cvsc)
+	if v.paths != nil {
+		desc := fmt.Sprintf("ClusterVaultSecretClaim %q", cvsc.Name)
+		errs = append(errs, v.checkTemplatePaths(&cvsc.Spec.Secret, field.NewPath("spec", "secret"), desc)...)
+	}
+	if len(errs) == 0 {
+		return allow()
+	}
+
+	v.logger.Infof("ClusterVaultSecretClaim %q is rejected: %v", cvsc.Name, errs.ToAggregate())
+	status := apierrors.NewInvalid(v1alpha1.Kind("ClusterVaultSecretClaim"), cvsc.Name, errs).ErrStatus
+	return &AdmissionResponse{Allowed: false, Result: &status}
+}
+
+func (v *Validator) validatePathPolicy(req *AdmissionRequest) *AdmissionResponse {
+	var vpp v1alpha1.VaultPathPolicy
+	if err := json.Unmarshal(req.Object.Raw, &vpp); err != nil {
+		return deny(fmt.Errorf("decode vault path policy: %v", err))
+	}
+
+	errs := validation.ValidateVaultPathPolicy(&vpp)
+	if len(errs) == 0 {
+		return allow()
+	}
+
+	v.logger.Infof("VaultPathPolicy %q is rejected: %v", vpp.Name, errs.ToAggregate())
+	status := apierrors.NewInvalid(v1alpha1.Kind("VaultPathPolicy"), vpp.Name, errs).ErrStatus
+	return &AdmissionResponse{Allowed: false, Result: &status}
+}
+
+// checkPaths checks that Vault paths of data items of the claim can be read.
+func (v *Validator) checkPaths(vsc *v1alpha1.VaultSecretClaim) field.ErrorList {
+	var allErrs field.ErrorList
+
+	specPath := field.NewPath("spec")
+	desc := fmt.Sprintf("VaultSecretClaim \"%s/%s\"", vsc.Namespace, vsc.Name)
+	if vsc.Spec.TemplateRef == nil {
+		allErrs = append(allErrs, v.checkTemplatePaths(&vsc.Spec.Secret, specPath.Child("secret"), desc)...)
+	}
+	for i := range vsc.Spec.Secrets {
+		allErrs = append(allErrs, v.checkTemplatePaths(&vsc.Spec.Secrets[i], specPath.Child("secrets").Index(i), desc)...)
+	}
+	return allErrs
+}
+
+// checkTemplatePaths checks that Vault paths of data items of the secret
+// template can be read. The claim described by desc is not rejected if Vault
+// can't be reached, since it will be retried on sync.
+func (v *Validator) checkTemplatePaths(tmpl *v1alpha1.SecretTemplate, fldPath *field.Path, desc string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, item := range tmpl.Data {
+		if item.VaultPath == "" {
+			continue
+		}
+		ok, err := v.paths.CanRead(item.VaultPath)
+		if err != nil {
+			v.logger.Warnf("Couldn't check Vault path %q of %s: %v", item.VaultPath, desc, err)
+			continue
+		}
+		if !ok {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("data").Index(i).Child("vaultPath"), fmt.Sprintf("Vault policy doesn't allow dweller to read %q", item.VaultPath)))
+		}
+	}
+	return allErrs
+}